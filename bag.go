@@ -0,0 +1,168 @@
+package zdutil
+
+import "sort"
+
+// BagCount pairs an element of a Bag with the number of times it occurs.
+type BagCount[T comparable] struct {
+	Value T
+	Count int
+}
+
+// Bag is a multiset that counts how many times each element was added.
+type Bag[T comparable] struct {
+	counts map[T]int
+	total  int
+}
+
+// NewBag creates a new bag containing the specified elements.
+// Unlike NewSet, duplicates in the input are counted.
+func NewBag[T comparable](elems ...T) *Bag[T] {
+	b := &Bag[T]{counts: make(map[T]int, len(elems))}
+
+	for i := range elems {
+		b.Add(elems[i], 1)
+	}
+
+	return b
+}
+
+// Add adds n occurrences of the element to the bag.
+// The function is a no-op if n is less than 1.
+func (b *Bag[T]) Add(element T, n int) {
+	if n < 1 {
+		return
+	}
+
+	if b.counts == nil {
+		b.counts = make(map[T]int)
+	}
+
+	b.counts[element] += n
+	b.total += n
+}
+
+// Remove deletes up to n occurrences of the element from the bag.
+// The element is removed entirely once its count reaches zero.
+func (b *Bag[T]) Remove(element T, n int) {
+	count, ok := b.counts[element]
+	if !ok || n < 1 {
+		return
+	}
+
+	if n >= count {
+		delete(b.counts, element)
+		b.total -= count
+		return
+	}
+
+	b.counts[element] = count - n
+	b.total -= n
+}
+
+// Count returns the number of times the element occurs in the bag.
+func (b *Bag[T]) Count(element T) int {
+	return b.counts[element]
+}
+
+// Contains checks if the bag contains at least one occurrence of all of the given
+// elements, following the same rules as Set.Contains.
+func (b *Bag[T]) Contains(elems ...T) bool {
+	if len(elems) == 0 && b.Len() > 0 {
+		return false
+	}
+
+	for i := range elems {
+		if _, ok := b.counts[elems[i]]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Len returns the total number of occurrences in the bag.
+func (b *Bag[T]) Len() int {
+	return b.total
+}
+
+// Clear resets the bag to its initial state, removing all elements.
+func (b *Bag[T]) Clear() {
+	b.counts = make(map[T]int)
+	b.total = 0
+}
+
+// Each calls fn once for every occurrence of every element in the bag,
+// in unspecified order, until fn returns false.
+func (b *Bag[T]) Each(fn func(T) bool) {
+	for elem, count := range b.counts {
+		for i := 0; i < count; i++ {
+			if !fn(elem) {
+				return
+			}
+		}
+	}
+}
+
+// Distinct returns a set containing each element of the bag once.
+func (b *Bag[T]) Distinct() *Set[T] {
+	set := &Set[T]{elements: make(map[T]struct{}, len(b.counts))}
+	for elem := range b.counts {
+		set.elements[elem] = struct{}{}
+	}
+	return set
+}
+
+// MostCommon returns the k elements with the highest counts, from most to least common.
+// If k is less than 0 or greater than the number of distinct elements, every element
+// is returned. Ties are broken by the natural order of T when it is an integer, float
+// or string type, otherwise their order is unspecified.
+func (b *Bag[T]) MostCommon(k int) []BagCount[T] {
+	values := make([]T, 0, len(b.counts))
+	for elem := range b.counts {
+		values = append(values, elem)
+	}
+	sortValues(values)
+
+	counts := make([]BagCount[T], len(values))
+	for i := range values {
+		counts[i] = BagCount[T]{Value: values[i], Count: b.counts[values[i]]}
+	}
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+
+	if k >= 0 && k < len(counts) {
+		counts = counts[:k]
+	}
+	return counts
+}
+
+// Union returns a new bag containing every element of b and a, each with the
+// larger of its two counts.
+func (b *Bag[T]) Union(a *Bag[T]) *Bag[T] {
+	union := NewBag[T]()
+
+	for elem, count := range b.counts {
+		union.Add(elem, count)
+	}
+	for elem, count := range a.counts {
+		if count > union.counts[elem] {
+			union.Add(elem, count-union.counts[elem])
+		}
+	}
+
+	return union
+}
+
+// Intersect returns a new bag containing the elements that exist in both b and a,
+// each with the smaller of its two counts.
+func (b *Bag[T]) Intersect(a *Bag[T]) *Bag[T] {
+	intersect := NewBag[T]()
+
+	for elem, count := range b.counts {
+		if other := a.counts[elem]; other < count {
+			count = other
+		}
+		intersect.Add(elem, count)
+	}
+
+	return intersect
+}
@@ -0,0 +1,38 @@
+package zdutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBag(t *testing.T) {
+	bag := NewBag("go", "rust", "go", "zig", "go", "rust")
+	bag.Add("c", 2)
+	bag.Remove("zig", 5)
+
+	if bag.Len() != 7 || bag.Count("go") != 3 || bag.Count("zig") != 0 || !bag.Distinct().Equal(*NewSet("go", "rust", "c")) {
+		fmt.Printf("[ERROR] failed to count bag elements:\n\t[len=%d]\n\t[distinct=%v]\n", bag.Len(), bag.Distinct().Values())
+		t.FailNow()
+	}
+
+	want := []BagCount[string]{{"go", 3}, {"c", 2}}
+	got := bag.MostCommon(2)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to get most common elements:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	other := NewBag("go", "rust", "rust", "rust", "java")
+
+	union := bag.Union(other)
+	if union.Count("go") != 3 || union.Count("rust") != 3 || union.Count("java") != 1 || union.Len() != 9 {
+		fmt.Printf("[ERROR] failed to union bags:\n\t[got=%v]\n", union.MostCommon(-1))
+		t.FailNow()
+	}
+
+	intersect := bag.Intersect(other)
+	if intersect.Count("go") != 1 || intersect.Count("rust") != 2 || intersect.Contains("c") || intersect.Len() != 3 {
+		fmt.Printf("[ERROR] failed to intersect bags:\n\t[got=%v]\n", intersect.MostCommon(-1))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,202 @@
+package zdutil
+
+import "math/bits"
+
+const wordSize = 64
+
+// BitSet is a compact set of non-negative integers backed by a slice of 64 bit words.
+// It grows on demand and uses one bit per integer up to the largest one stored,
+// which makes it much smaller than Set[int] for dense integer domains.
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet creates a new bit set with the specified bits set.
+// Negative values are ignored.
+func NewBitSet(elems ...int) *BitSet {
+	b := &BitSet{}
+	for i := range elems {
+		b.Set(elems[i])
+	}
+	return b
+}
+
+// BitSetFromSet creates a new bit set containing the elements of s.
+// Negative values are ignored.
+func BitSetFromSet(s *Set[int]) *BitSet {
+	b := &BitSet{}
+	for elem := range s.elements {
+		b.Set(elem)
+	}
+	return b
+}
+
+// ToSet returns a Set containing every bit that is set.
+func (b *BitSet) ToSet() *Set[int] {
+	set := &Set[int]{elements: make(map[int]struct{}, b.Count())}
+	b.Each(func(i int) bool {
+		set.elements[i] = struct{}{}
+		return true
+	})
+	return set
+}
+
+// Set sets bit i, growing the bit set if needed.
+// The function is a no-op if i is negative.
+func (b *BitSet) Set(i int) {
+	if i < 0 {
+		return
+	}
+
+	w := i / wordSize
+	if w >= len(b.words) {
+		b.grow(w + 1)
+	}
+
+	b.words[w] |= 1 << uint(i%wordSize)
+}
+
+// grow extends the bit set to n words, doubling the capacity so that setting
+// increasing bits one at a time stays amortized constant time.
+func (b *BitSet) grow(n int) {
+	if n <= cap(b.words) {
+		b.words = b.words[:n]
+		return
+	}
+
+	words := make([]uint64, n, 2*n)
+	copy(words, b.words)
+	b.words = words
+}
+
+// Clear clears bit i. The function is a no-op if i is negative or out of range.
+func (b *BitSet) Clear(i int) {
+	if i < 0 || i/wordSize >= len(b.words) {
+		return
+	}
+
+	b.words[i/wordSize] &^= 1 << uint(i%wordSize)
+}
+
+// Test reports whether bit i is set.
+func (b *BitSet) Test(i int) bool {
+	if i < 0 || i/wordSize >= len(b.words) {
+		return false
+	}
+
+	return b.words[i/wordSize]&(1<<uint(i%wordSize)) != 0
+}
+
+// Contains checks if all of the given bits are set, following the same rules as Set.Contains.
+func (b *BitSet) Contains(elems ...int) bool {
+	if len(elems) == 0 && b.Count() > 0 {
+		return false
+	}
+
+	for i := range elems {
+		if !b.Test(elems[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns the number of bits that are set.
+func (b *BitSet) Count() int {
+	count := 0
+	for _, w := range b.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Reset clears every bit and releases the underlying storage.
+func (b *BitSet) Reset() {
+	b.words = nil
+}
+
+// NextSet returns the index of the first set bit at or after i and true,
+// or zero and false if there is no such bit.
+func (b *BitSet) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+
+	w := i / wordSize
+	if w >= len(b.words) {
+		return 0, false
+	}
+
+	word := b.words[w] >> uint(i%wordSize)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word), true
+	}
+
+	for w++; w < len(b.words); w++ {
+		if b.words[w] != 0 {
+			return w*wordSize + bits.TrailingZeros64(b.words[w]), true
+		}
+	}
+
+	return 0, false
+}
+
+// Each calls fn for every set bit, in increasing order, until fn returns false.
+func (b *BitSet) Each(fn func(int) bool) {
+	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+		if !fn(i) {
+			return
+		}
+	}
+}
+
+// And returns a new bit set with the bits that are set in both b and a.
+func (b *BitSet) And(a *BitSet) *BitSet {
+	n := len(b.words)
+	if len(a.words) < n {
+		n = len(a.words)
+	}
+
+	res := &BitSet{words: make([]uint64, n)}
+	for i := range res.words {
+		res.words[i] = b.words[i] & a.words[i]
+	}
+	return res
+}
+
+// Or returns a new bit set with the bits that are set in b, in a, or in both.
+func (b *BitSet) Or(a *BitSet) *BitSet {
+	return b.combine(a, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor returns a new bit set with the bits that are set in exactly one of b and a.
+func (b *BitSet) Xor(a *BitSet) *BitSet {
+	return b.combine(a, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// AndNot returns a new bit set with the bits that are set in b but not in a.
+func (b *BitSet) AndNot(a *BitSet) *BitSet {
+	return b.combine(a, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// combine applies op word by word, treating missing words of the shorter set as zero.
+func (b *BitSet) combine(a *BitSet, op func(x, y uint64) uint64) *BitSet {
+	n := len(b.words)
+	if len(a.words) > n {
+		n = len(a.words)
+	}
+
+	res := &BitSet{words: make([]uint64, n)}
+	for i := range res.words {
+		var x, y uint64
+		if i < len(b.words) {
+			x = b.words[i]
+		}
+		if i < len(a.words) {
+			y = a.words[i]
+		}
+		res.words[i] = op(x, y)
+	}
+	return res
+}
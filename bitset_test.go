@@ -0,0 +1,124 @@
+package zdutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBitSet(t *testing.T) {
+	a := NewBitSet(1, 3, 64, 130)
+	b := NewBitSet(3, 64, 65)
+
+	if !a.Test(130) || a.Test(2) || a.Test(1000) || a.Count() != 4 {
+		fmt.Printf("[ERROR] failed to set bits:\n\t[got=%v]\n", a.ToSet().Values())
+		t.FailNow()
+	}
+
+	cases := map[string]struct {
+		got  *BitSet
+		want []int
+	}{
+		"and":    {a.And(b), []int{3, 64}},
+		"or":     {a.Or(b), []int{1, 3, 64, 65, 130}},
+		"xor":    {a.Xor(b), []int{1, 65, 130}},
+		"andnot": {a.AndNot(b), []int{1, 130}},
+	}
+
+	for name, c := range cases {
+		got := Collect[int](c.got)
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			fmt.Printf("[ERROR] failed bit operation:\n\t[op=%s]\n\t[got=%v]\n\t[want=%v]\n", name, got, c.want)
+			t.FailNow()
+		}
+	}
+
+	a.Clear(64)
+	if next, ok := a.NextSet(4); !ok || next != 130 {
+		fmt.Printf("[ERROR] failed to find next set bit:\n\t[got=%d]\n\t[want=%d]\n", next, 130)
+		t.FailNow()
+	}
+
+	set := NewSet(5, 70, 200)
+	if !BitSetFromSet(set).ToSet().Equal(*set) {
+		fmt.Printf("[ERROR] failed to convert between bit set and set:\n\t[got=%v]\n", BitSetFromSet(set).ToSet().Values())
+		t.FailNow()
+	}
+}
+
+const benchDomain = 1 << 16
+
+func BenchmarkBitSetAdd(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		set := NewBitSet()
+		for j := 0; j < benchDomain; j += 2 {
+			set.Set(j)
+		}
+	}
+}
+
+func BenchmarkSetAdd(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		set := NewSet[int]()
+		for j := 0; j < benchDomain; j += 2 {
+			set.Add(j)
+		}
+	}
+}
+
+func BenchmarkBitSetContains(b *testing.B) {
+	set := NewBitSet()
+	for j := 0; j < benchDomain; j += 2 {
+		set.Set(j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Test(i % benchDomain)
+	}
+}
+
+func BenchmarkSetContains(b *testing.B) {
+	set := NewSet[int]()
+	for j := 0; j < benchDomain; j += 2 {
+		set.Add(j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(i % benchDomain)
+	}
+}
+
+func BenchmarkBitSetAnd(b *testing.B) {
+	x, y := NewBitSet(), NewBitSet()
+	for j := 0; j < benchDomain; j++ {
+		if j%2 == 0 {
+			x.Set(j)
+		}
+		if j%3 == 0 {
+			y.Set(j)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.And(y)
+	}
+}
+
+func BenchmarkSetIntersect(b *testing.B) {
+	x, y := NewSet[int](), NewSet[int]()
+	for j := 0; j < benchDomain; j++ {
+		if j%2 == 0 {
+			x.Add(j)
+		}
+		if j%3 == 0 {
+			y.Add(j)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Intersect(*y)
+	}
+}
@@ -0,0 +1,154 @@
+package zdutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// hashKey returns two 64 bit hashes of key. The FNV-1a hash of the key is
+// passed through the splitmix64 finalizer with two different seeds, since the
+// raw FNV bits are poorly mixed for short keys.
+func hashKey(key []byte) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write(key)
+	sum := h.Sum64()
+	return mix64(sum), mix64(sum ^ 0x9e3779b97f4a7c15)
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// maxBloomHashes bounds the number of hashes of a BloomFilter, which is already
+// enough for a false positive rate of 2^-64.
+const maxBloomHashes = 64
+
+// BloomFilter is a probabilistic set of keys. Testing a key that was added always
+// returns true, while testing a key that was not added returns true with a
+// probability close to the false positive rate the filter was sized for.
+// Keys cannot be removed, see CuckooFilter for a filter that supports deletion.
+// Filters must be created with NewBloomFilter, since the zero value has no bits
+// to set. Adding to it panics and testing it always returns false.
+type BloomFilter struct {
+	bits   BitSet
+	m      uint64
+	hashes uint64
+}
+
+// NewBloomFilter creates a bloom filter sized to hold expectedItems keys with the
+// given false positive rate, for example 0.01 for one percent.
+func NewBloomFilter(expectedItems uint, falsePositiveRate float64) *BloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / n * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	if k > maxBloomHashes {
+		k = maxBloomHashes
+	}
+
+	f := &BloomFilter{m: uint64(m), hashes: uint64(k)}
+	f.bits.grow(int((f.m + wordSize - 1) / wordSize))
+
+	return f
+}
+
+// Add adds the key to the filter.
+func (f *BloomFilter) Add(key []byte) {
+	if f.m == 0 {
+		panic("zdutil: Add called on BloomFilter not created with NewBloomFilter")
+	}
+
+	h1, h2 := hashKey(key)
+	for i := uint64(0); i < f.hashes; i++ {
+		f.bits.Set(int((h1 + i*h2) % f.m))
+	}
+}
+
+// AddString adds the string key to the filter.
+func (f *BloomFilter) AddString(key string) {
+	f.Add([]byte(key))
+}
+
+// Test reports whether the key may have been added to the filter.
+// A false result means the key was definitely never added.
+func (f *BloomFilter) Test(key []byte) bool {
+	if f.m == 0 {
+		return false
+	}
+
+	h1, h2 := hashKey(key)
+	for i := uint64(0); i < f.hashes; i++ {
+		if !f.bits.Test(int((h1 + i*h2) % f.m)) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestString reports whether the string key may have been added to the filter.
+func (f *BloomFilter) TestString(key string) bool {
+	return f.Test([]byte(key))
+}
+
+// Reset removes every key from the filter, keeping its size.
+func (f *BloomFilter) Reset() {
+	for i := range f.bits.words {
+		f.bits.words[i] = 0
+	}
+}
+
+// MarshalBinary encodes the filter, including its size and number of hashes,
+// so that it can be persisted and restored with UnmarshalBinary.
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 16+8*len(f.bits.words))
+	binary.BigEndian.PutUint64(data[0:], f.m)
+	binary.BigEndian.PutUint64(data[8:], f.hashes)
+	for i, w := range f.bits.words {
+		binary.BigEndian.PutUint64(data[16+8*i:], w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary replaces the filter with one decoded from data produced by MarshalBinary.
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("bloom filter data is too short [length=%d]", len(data))
+	}
+
+	m := binary.BigEndian.Uint64(data[0:])
+	hashes := binary.BigEndian.Uint64(data[8:])
+	// Round up without adding to m, which could overflow for malformed data.
+	words := m / wordSize
+	if m%wordSize != 0 {
+		words++
+	}
+	if m == 0 || hashes == 0 || hashes > maxBloomHashes || uint64(len(data)-16) != 8*words {
+		return fmt.Errorf("bloom filter data is malformed [length=%d] [bits=%d] [hashes=%d]", len(data), m, hashes)
+	}
+
+	f.m = m
+	f.hashes = hashes
+	f.bits.words = make([]uint64, words)
+	for i := range f.bits.words {
+		f.bits.words[i] = binary.BigEndian.Uint64(data[16+8*i:])
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package zdutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	filter := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.AddString(strconv.Itoa(i))
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !filter.TestString(strconv.Itoa(i)) {
+			fmt.Printf("[ERROR] bloom filter lost added key:\n\t[key=%d]\n", i)
+			t.FailNow()
+		}
+	}
+
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if filter.TestString(strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+
+	if rate := float64(falsePositives) / 10000; rate > 0.02 {
+		fmt.Printf("[ERROR] bloom filter false positive rate is too high:\n\t[got=%f]\n\t[want=%f]\n", rate, 0.01)
+		t.FailNow()
+	}
+
+	data, _ := filter.MarshalBinary()
+	restored := &BloomFilter{}
+	if err := restored.UnmarshalBinary(data); err != nil || !restored.TestString("42") {
+		fmt.Printf("[ERROR] failed to restore bloom filter:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	if err := restored.UnmarshalBinary(data[:20]); err == nil {
+		fmt.Println("[ERROR] expected error when restoring truncated bloom filter")
+		t.FailNow()
+	}
+}
+
+func TestCuckooFilter(t *testing.T) {
+	filter := NewCuckooFilter(1000)
+	for i := 0; i < 1000; i++ {
+		if !filter.AddString(strconv.Itoa(i)) {
+			fmt.Printf("[ERROR] cuckoo filter failed to add key:\n\t[key=%d]\n", i)
+			t.FailNow()
+		}
+	}
+
+	for i := 0; i < 1000; i += 2 {
+		if !filter.RemoveString(strconv.Itoa(i)) {
+			fmt.Printf("[ERROR] cuckoo filter failed to remove key:\n\t[key=%d]\n", i)
+			t.FailNow()
+		}
+	}
+
+	for i := 1; i < 1000; i += 2 {
+		if !filter.TestString(strconv.Itoa(i)) {
+			fmt.Printf("[ERROR] cuckoo filter lost added key:\n\t[key=%d]\n", i)
+			t.FailNow()
+		}
+	}
+
+	if filter.Len() != 500 {
+		fmt.Printf("[ERROR] cuckoo filter has wrong length:\n\t[got=%d]\n\t[want=%d]\n", filter.Len(), 500)
+		t.FailNow()
+	}
+
+	data, _ := filter.MarshalBinary()
+	restored := &CuckooFilter{}
+	if err := restored.UnmarshalBinary(data); err != nil || !restored.TestString("41") || restored.Len() != 500 {
+		fmt.Printf("[ERROR] failed to restore cuckoo filter:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	// a full filter must refuse new keys without losing existing ones
+	small := NewCuckooFilter(8)
+	added := []string{}
+	for i := 0; i < 100; i++ {
+		if small.AddString(strconv.Itoa(i)) {
+			added = append(added, strconv.Itoa(i))
+		}
+	}
+	for _, key := range added {
+		if !small.TestString(key) {
+			fmt.Printf("[ERROR] full cuckoo filter lost added key:\n\t[key=%s]\n", key)
+			t.FailNow()
+		}
+	}
+}
+
+func TestFilterMalformedData(t *testing.T) {
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint64(header, 1<<61)
+	binary.BigEndian.PutUint64(header[8:], 1)
+	if err := (&CuckooFilter{}).UnmarshalBinary(header); err == nil {
+		fmt.Println("[ERROR] expected cuckoo filter with overflowing size to be rejected")
+		t.FailNow()
+	}
+
+	binary.BigEndian.PutUint64(header, ^uint64(0)-1)
+	if err := (&BloomFilter{}).UnmarshalBinary(header); err == nil {
+		fmt.Println("[ERROR] expected bloom filter with overflowing size to be rejected")
+		t.FailNow()
+	}
+
+	data, _ := NewBloomFilter(10, 0.01).MarshalBinary()
+	binary.BigEndian.PutUint64(data[8:], 1<<62)
+	if err := (&BloomFilter{}).UnmarshalBinary(data); err == nil {
+		fmt.Println("[ERROR] expected bloom filter with too many hashes to be rejected")
+		t.FailNow()
+	}
+
+	var cuckoo CuckooFilter
+	var bloom BloomFilter
+	if cuckoo.AddString("a") || cuckoo.TestString("a") || cuckoo.RemoveString("a") || bloom.TestString("a") {
+		fmt.Println("[ERROR] expected zero value filters to be empty")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,105 @@
+package zdutil
+
+// Sized is implemented by containers that can report how many elements they hold.
+type Sized interface {
+	Len() int
+}
+
+// Clearable is implemented by containers that can remove all of their elements.
+type Clearable interface {
+	Clear()
+}
+
+// Container is implemented by containers that can test for membership.
+type Container[T any] interface {
+	Contains(elems ...T) bool
+}
+
+// Iterable is implemented by containers that can visit each of their elements.
+// Iteration stops early when fn returns false.
+type Iterable[T any] interface {
+	Each(fn func(T) bool)
+}
+
+// Collection groups the interfaces shared by every container in this package.
+type Collection[T any] interface {
+	Sized
+	Clearable
+	Iterable[T]
+}
+
+var (
+	_ Collection[int] = (*Stack[int])(nil)
+	_ Collection[int] = (*Set[int])(nil)
+	_ Container[int]  = (*Set[int])(nil)
+	_ Collection[int] = (*ConcurrentSet[int])(nil)
+	_ Container[int]  = (*ConcurrentSet[int])(nil)
+	_ Collection[int] = (*OrderedSet[int])(nil)
+	_ Container[int]  = (*OrderedSet[int])(nil)
+	_ Collection[int] = (*SortedSet[int])(nil)
+	_ Container[int]  = (*SortedSet[int])(nil)
+	_ Collection[int] = (*Bag[int])(nil)
+	_ Container[int]  = (*Bag[int])(nil)
+	_ Iterable[int]   = (*BitSet)(nil)
+	_ Container[int]  = (*BitSet)(nil)
+	_ Collection[int] = (*HashSet[int])(nil)
+	_ Container[int]  = (*HashSet[int])(nil)
+	_ Collection[int] = (*ExpiringSet[int])(nil)
+	_ Container[int]  = (*ExpiringSet[int])(nil)
+	_ Collection[int] = (*RingBuffer[int])(nil)
+	_ Collection[int] = (*SyncRingBuffer[int])(nil)
+)
+
+// IsEmpty reports whether the container holds no elements.
+func IsEmpty(c Sized) bool {
+	return c.Len() == 0
+}
+
+// Collect returns every element of the iterable as a slice, in iteration order.
+func Collect[T any](src Iterable[T]) []T {
+	var res []T
+	src.Each(func(elem T) bool {
+		res = append(res, elem)
+		return true
+	})
+	return res
+}
+
+// CollectInto passes every element of src to dst, in iteration order.
+// dst is usually a method value such as set.Add or stack.Push.
+func CollectInto[T any](dst func(elems ...T), src Iterable[T]) {
+	src.Each(func(elem T) bool {
+		dst(elem)
+		return true
+	})
+}
+
+// Drain returns every element of the container as a slice and then clears it.
+// The two steps are not atomic, so elements added concurrently in between may be lost.
+func Drain[T any, C interface {
+	Iterable[T]
+	Clearable
+}](c C) []T {
+	res := Collect[T](c)
+	c.Clear()
+	return res
+}
+
+// Ordered is satisfied by every type whose values can be compared with < and >.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// compareOrdered returns -1, 0 or 1 depending on whether a is less than,
+// equal to or greater than b.
+func compareOrdered[T Ordered](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
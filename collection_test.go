@@ -0,0 +1,31 @@
+package zdutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCollection(t *testing.T) {
+	stack := NewStack(1, 2, 3)
+
+	set := NewSet[int]()
+	CollectInto[int](set.Add, &stack)
+	if set.Len() != 3 || !set.Contains(1, 2, 3) {
+		fmt.Printf("[ERROR] failed to collect stack into set:\n\t[got=%v]\n\t[want=%v]\n", set.Values(), []int{1, 2, 3})
+		t.FailNow()
+	}
+
+	want := []int{1, 2, 3}
+	got := Drain[int](&stack)
+	if fmt.Sprint(got) != fmt.Sprint(want) || !IsEmpty(&stack) {
+		fmt.Printf("[ERROR] failed to drain stack:\n\t[got=%v]\n\t[want=%v]\n\t[len=%d]\n", got, want, stack.Len())
+		t.FailNow()
+	}
+
+	ring := NewRingBuffer[int](2)
+	ring.Push(Collect[int](set)...)
+	if ring.Len() != 2 {
+		fmt.Printf("[ERROR] failed to collect set into ring:\n\t[got=%v]\n", ring.Snapshot())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,213 @@
+package zdutil
+
+// Iterator lazily yields values one at a time:
+//
+//	for it.Next() {
+//		v := it.Value()
+//	}
+//
+// Iterators also implement Iterable, so they can be passed to Collect.
+// Once exhausted they cannot be restarted.
+type Iterator[T any] interface {
+	Iterable[T]
+	Next() bool
+	Value() T
+}
+
+// The combinatorics functions below take slices and yield their results in a
+// deterministic order derived from the order of the input. To use them with a
+// Set, pass SortedValues(set) for a deterministic order or set.Values() otherwise.
+
+// indexIterator walks a sequence of index vectors, mapping each one to a slice of values.
+type indexIterator[T any] struct {
+	idx     []int
+	step    func(idx []int) bool
+	value   func(idx []int) []T
+	started bool
+	done    bool
+	current []T
+}
+
+func (it *indexIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+
+	if it.started && !it.step(it.idx) {
+		it.done = true
+		it.current = nil
+		return false
+	}
+
+	it.started = true
+	it.current = it.value(it.idx)
+	return true
+}
+
+// Value returns the current slice. Every call to Next yields a new slice,
+// so it is safe to keep.
+func (it *indexIterator[T]) Value() []T {
+	return it.current
+}
+
+// Each calls fn with every remaining value until fn returns false.
+func (it *indexIterator[T]) Each(fn func([]T) bool) {
+	for it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
+
+func pick[T any](elems []T) func(idx []int) []T {
+	return func(idx []int) []T {
+		res := make([]T, len(idx))
+		for i := range idx {
+			res[i] = elems[idx[i]]
+		}
+		return res
+	}
+}
+
+// Combinations lazily yields every way of choosing k elements from elems, in
+// lexicographic order of their positions. Each combination keeps the input order.
+// It yields nothing when k is negative or greater than len(elems).
+func Combinations[T any](elems []T, k int) Iterator[[]T] {
+	n := len(elems)
+	it := &indexIterator[T]{value: pick(elems)}
+	if k < 0 || k > n {
+		it.done = true
+		return it
+	}
+
+	it.idx = make([]int, k)
+	for i := range it.idx {
+		it.idx[i] = i
+	}
+
+	it.step = func(idx []int) bool {
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return false
+		}
+
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+		return true
+	}
+
+	return it
+}
+
+// Permutations lazily yields every ordering of elems, in lexicographic order of
+// their positions, starting with elems itself.
+func Permutations[T any](elems []T) Iterator[[]T] {
+	it := &indexIterator[T]{value: pick(elems)}
+
+	it.idx = make([]int, len(elems))
+	for i := range it.idx {
+		it.idx[i] = i
+	}
+
+	it.step = func(idx []int) bool {
+		i := len(idx) - 2
+		for i >= 0 && idx[i] >= idx[i+1] {
+			i--
+		}
+		if i < 0 {
+			return false
+		}
+
+		j := len(idx) - 1
+		for idx[j] <= idx[i] {
+			j--
+		}
+		idx[i], idx[j] = idx[j], idx[i]
+
+		for l, r := i+1, len(idx)-1; l < r; l, r = l+1, r-1 {
+			idx[l], idx[r] = idx[r], idx[l]
+		}
+		return true
+	}
+
+	return it
+}
+
+// CartesianProduct lazily yields every tuple made of one element from each slice,
+// varying the last slice fastest. It yields nothing if any slice is empty.
+func CartesianProduct[T any](slices ...[]T) Iterator[[]T] {
+	it := &indexIterator[T]{
+		idx: make([]int, len(slices)),
+		value: func(idx []int) []T {
+			res := make([]T, len(idx))
+			for i := range idx {
+				res[i] = slices[i][idx[i]]
+			}
+			return res
+		},
+	}
+
+	for i := range slices {
+		if len(slices[i]) == 0 {
+			it.done = true
+			return it
+		}
+	}
+
+	it.step = func(idx []int) bool {
+		for i := len(idx) - 1; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(slices[i]) {
+				return true
+			}
+			idx[i] = 0
+		}
+		return false
+	}
+
+	return it
+}
+
+type powerSetIterator[T any] struct {
+	elems []T
+	k     int
+	inner Iterator[[]T]
+}
+
+// PowerSet lazily yields every subset of elems, ordered by size and then in the
+// order of Combinations, starting with the empty subset.
+func PowerSet[T any](elems []T) Iterator[[]T] {
+	return &powerSetIterator[T]{elems: elems, inner: Combinations(elems, 0)}
+}
+
+func (it *powerSetIterator[T]) Next() bool {
+	for !it.inner.Next() {
+		if it.k >= len(it.elems) {
+			return false
+		}
+
+		it.k++
+		it.inner = Combinations(it.elems, it.k)
+	}
+	return true
+}
+
+// Value returns the current subset. Every call to Next yields a new slice,
+// so it is safe to keep.
+func (it *powerSetIterator[T]) Value() []T {
+	return it.inner.Value()
+}
+
+// Each calls fn with every remaining subset until fn returns false.
+func (it *powerSetIterator[T]) Each(fn func([]T) bool) {
+	for it.Next() {
+		if !fn(it.Value()) {
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package zdutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCombinatorics(t *testing.T) {
+	cases := map[string]struct {
+		got  Iterator[[]int]
+		want string
+	}{
+		"combinations":       {Combinations([]int{1, 2, 3, 4}, 2), "[[1 2] [1 3] [1 4] [2 3] [2 4] [3 4]]"},
+		"combinations empty": {Combinations([]int{1, 2}, 3), "[]"},
+		"combinations zero":  {Combinations([]int{1, 2}, 0), "[[]]"},
+		"permutations":       {Permutations([]int{1, 2, 3}), "[[1 2 3] [1 3 2] [2 1 3] [2 3 1] [3 1 2] [3 2 1]]"},
+		"product":            {CartesianProduct([]int{1, 2}, []int{3}, []int{4, 5}), "[[1 3 4] [1 3 5] [2 3 4] [2 3 5]]"},
+		"product empty":      {CartesianProduct([]int{1, 2}, []int{}), "[]"},
+		"power set":          {PowerSet(SortedValues(NewSet(3, 1, 2))), "[[] [1] [2] [3] [1 2] [1 3] [2 3] [1 2 3]]"},
+	}
+
+	for name, c := range cases {
+		got := fmt.Sprint(Collect[[]int](c.got))
+		if got != c.want {
+			fmt.Printf("[ERROR] failed to generate %s:\n\t[got=%s]\n\t[want=%s]\n", name, got, c.want)
+			t.Fail()
+		}
+	}
+
+	// iterators are lazy, so a huge space can be sampled without materializing it
+	it := Permutations(make([]int, 20))
+	count := 0
+	for count < 3 && it.Next() {
+		count++
+	}
+	if count != 3 {
+		fmt.Printf("[ERROR] failed to iterate lazily:\n\t[got=%d]\n\t[want=%d]\n", count, 3)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,277 @@
+package zdutil
+
+import "sync"
+
+// ConcurrentSet is a Set that is safe for concurrent use.
+type ConcurrentSet[T comparable] struct {
+	set Set[T]
+	m   sync.RWMutex
+}
+
+// NewConcurrentSet creates a new concurrent set containing the specified elements.
+// Duplicates in the input are ignored, and only distinct elements are stored.
+func NewConcurrentSet[T comparable](elems ...T) *ConcurrentSet[T] {
+	return &ConcurrentSet[T]{set: *NewSet(elems...)}
+}
+
+// Add adds the specified elements to the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Add(elems ...T) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.set.Add(elems...)
+}
+
+// AddIfAbsent adds the element to the set and reports whether it was added,
+// that is whether it did not already exist in the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) AddIfAbsent(element T) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if _, ok := s.set.elements[element]; ok {
+		return false
+	}
+
+	s.set.Add(element)
+	return true
+}
+
+// Remove deletes the specified element from the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Remove(element T) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.set.Remove(element)
+}
+
+// RemoveIf deletes every element for which pred returns true and returns the
+// number of deleted elements. pred is called while holding the lock, so it must
+// not call back into the set.
+func (s *ConcurrentSet[T]) RemoveIf(pred func(T) bool) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	removed := 0
+	for elem := range s.set.elements {
+		if pred(elem) {
+			delete(s.set.elements, elem)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Contains checks if the set contains all of the given elements, following the
+// same rules as Set.Contains.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Contains(elems ...T) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.Contains(elems...)
+}
+
+// Len returns the number of elements in the set.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Len() int {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.Len()
+}
+
+// Clear removes all elements from the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Clear() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.set.Clear()
+}
+
+// Values returns all elements in the set.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Values() []T {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.Values()
+}
+
+// Each calls fn for every element in the set, in unspecified order, until fn
+// returns false. It iterates over a copy taken under the lock, so fn may safely
+// modify the set.
+func (s *ConcurrentSet[T]) Each(fn func(T) bool) {
+	for _, elem := range s.Values() {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// Snapshot returns a plain Set holding a copy of the current elements.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Snapshot() *Set[T] {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	snapshot := NewSet[T]()
+	for elem := range s.set.elements {
+		snapshot.elements[elem] = struct{}{}
+	}
+
+	return snapshot
+}
+
+// Intersect returns a new set containing the elements that exist in both s and a.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Intersect(a Set[T]) Set[T] {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.Intersect(a)
+}
+
+// Union returns a new set containing the elements that exist in s, in a, or in both.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Union(a Set[T]) Set[T] {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.Union(a)
+}
+
+// ExceptRight returns a new set containing the elements of s that do not exist in a.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) ExceptRight(a Set[T]) Set[T] {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.ExceptRight(a)
+}
+
+// ExceptLeft returns a new set containing the elements of a that do not exist in s.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) ExceptLeft(a Set[T]) Set[T] {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.ExceptLeft(a)
+}
+
+// SymmetricDifference returns a new set containing the elements that exist in
+// exactly one of s and a.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) SymmetricDifference(a Set[T]) Set[T] {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.SymmetricDifference(a)
+}
+
+// IsSubset reports whether every element of s exists in a.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) IsSubset(a Set[T]) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.IsSubset(a)
+}
+
+// IsSuperset reports whether every element of a exists in s.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) IsSuperset(a Set[T]) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.IsSuperset(a)
+}
+
+// IsProperSubset reports whether s is a subset of a and a has at least one
+// element that does not exist in s.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) IsProperSubset(a Set[T]) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.IsProperSubset(a)
+}
+
+// Equal reports whether s and a contain exactly the same elements.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) Equal(a Set[T]) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.Equal(a)
+}
+
+// IsDisjoint reports whether s and a have no elements in common.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) IsDisjoint(a Set[T]) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.IsDisjoint(a)
+}
+
+// MarshalJSON encodes the set in the same way as Set.MarshalJSON.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) MarshalJSON() ([]byte, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.MarshalJSON()
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of the decoded JSON array.
+// It acquires a lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) UnmarshalJSON(data []byte) error {
+	var set Set[T]
+	if err := set.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.set = set
+	return nil
+}
+
+// GobEncode encodes the set in the same way as Set.GobEncode.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) GobEncode() ([]byte, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.GobEncode()
+}
+
+// GobDecode replaces the contents of the set with the decoded gob stream.
+// It acquires a lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) GobDecode(data []byte) error {
+	var set Set[T]
+	if err := set.GobDecode(data); err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.set = set
+	return nil
+}
+
+// MarshalText encodes the set in the same way as Set.MarshalText.
+// It acquires a read lock to ensure thread-safe access.
+func (s *ConcurrentSet[T]) MarshalText() ([]byte, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return s.set.MarshalText()
+}
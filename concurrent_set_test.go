@@ -0,0 +1,61 @@
+package zdutil
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrentSet(t *testing.T) {
+	set := NewConcurrentSet[int]()
+
+	var added int64
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if set.AddIfAbsent(j) {
+					atomic.AddInt64(&added, 1)
+				}
+				set.Contains(j, i)
+				if j%100 == 0 {
+					set.Snapshot()
+					set.Len()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if added != 1000 || set.Len() != 1000 {
+		fmt.Printf("[ERROR] failed to add each element exactly once:\n\t[added=%d]\n\t[len=%d]\n\t[want=%d]\n", added, set.Len(), 1000)
+		t.FailNow()
+	}
+
+	var removed int64
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			atomic.AddInt64(&removed, int64(set.RemoveIf(func(elem int) bool {
+				return elem%2 == 0
+			})))
+		}()
+	}
+	wg.Wait()
+
+	if removed != 500 || set.Len() != 500 || set.Contains(2) {
+		fmt.Printf("[ERROR] failed to remove even elements exactly once:\n\t[removed=%d]\n\t[len=%d]\n\t[want=%d]\n", removed, set.Len(), 500)
+		t.FailNow()
+	}
+
+	snapshot := set.Snapshot()
+	set.Clear()
+	if snapshot.Len() != 500 || !snapshot.Contains(1, 999) {
+		fmt.Printf("[ERROR] failed to keep snapshot independent of set:\n\t[len=%d]\n\t[want=%d]\n", snapshot.Len(), 500)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,219 @@
+package zdutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+)
+
+const (
+	cuckooBucketSize = 4
+	cuckooMaxKicks   = 500
+)
+
+type cuckooBucket [cuckooBucketSize]uint16
+
+// CuckooFilter is a probabilistic set of keys that, unlike BloomFilter, supports
+// removing keys. It stores a 16 bit fingerprint of every key in one of two
+// candidate buckets, which gives a false positive rate of roughly 0.01 percent.
+// Filters should be created with NewCuckooFilter. The zero value has no room for
+// any key, so Add always fails on it.
+type CuckooFilter struct {
+	buckets []cuckooBucket
+	count   uint64
+}
+
+// NewCuckooFilter creates a cuckoo filter with room for at least capacity keys.
+// The number of buckets is rounded up to a power of two that keeps the filter
+// at most 90 percent full, above which inserts start to fail.
+func NewCuckooFilter(capacity uint) *CuckooFilter {
+	n := uint64(1)
+	for n*cuckooBucketSize*9 < uint64(capacity)*10 {
+		n <<= 1
+	}
+
+	return &CuckooFilter{buckets: make([]cuckooBucket, n)}
+}
+
+// indexes returns the fingerprint of the key and its two candidate buckets.
+func (f *CuckooFilter) indexes(key []byte) (fp uint16, i1, i2 uint64) {
+	h1, h2 := hashKey(key)
+
+	fp = uint16(h2)
+	if fp == 0 {
+		fp = 1
+	}
+
+	i1 = h1 & uint64(len(f.buckets)-1)
+	return fp, i1, f.altIndex(i1, fp)
+}
+
+// altIndex returns the other candidate bucket of a fingerprint stored in bucket i.
+// Applying it twice returns i again.
+func (f *CuckooFilter) altIndex(i uint64, fp uint16) uint64 {
+	return (i ^ mix64(uint64(fp))) & uint64(len(f.buckets)-1)
+}
+
+func (f *CuckooFilter) insertInto(i uint64, fp uint16) bool {
+	for slot := range f.buckets[i] {
+		if f.buckets[i][slot] == 0 {
+			f.buckets[i][slot] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds the key to the filter and reports whether it succeeded. It fails when
+// the filter is too full to make room, in which case the filter is left unchanged.
+func (f *CuckooFilter) Add(key []byte) bool {
+	if len(f.buckets) == 0 {
+		return false
+	}
+
+	fp, i1, i2 := f.indexes(key)
+	if f.insertInto(i1, fp) || f.insertInto(i2, fp) {
+		f.count++
+		return true
+	}
+
+	type kick struct {
+		bucket uint64
+		slot   int
+	}
+	kicks := make([]kick, 0, cuckooMaxKicks)
+
+	i := i1
+	if rand.Intn(2) == 0 {
+		i = i2
+	}
+
+	for len(kicks) < cuckooMaxKicks {
+		slot := rand.Intn(cuckooBucketSize)
+		fp, f.buckets[i][slot] = f.buckets[i][slot], fp
+		kicks = append(kicks, kick{bucket: i, slot: slot})
+
+		i = f.altIndex(i, fp)
+		if f.insertInto(i, fp) {
+			f.count++
+			return true
+		}
+	}
+
+	// undo the evictions so that no fingerprint is lost
+	for k := len(kicks) - 1; k >= 0; k-- {
+		b := kicks[k]
+		fp, f.buckets[b.bucket][b.slot] = f.buckets[b.bucket][b.slot], fp
+	}
+
+	return false
+}
+
+// AddString adds the string key to the filter and reports whether it succeeded.
+func (f *CuckooFilter) AddString(key string) bool {
+	return f.Add([]byte(key))
+}
+
+// Test reports whether the key may have been added to the filter.
+// A false result means the key was definitely never added.
+func (f *CuckooFilter) Test(key []byte) bool {
+	if len(f.buckets) == 0 {
+		return false
+	}
+
+	fp, i1, i2 := f.indexes(key)
+	for slot := 0; slot < cuckooBucketSize; slot++ {
+		if f.buckets[i1][slot] == fp || f.buckets[i2][slot] == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// TestString reports whether the string key may have been added to the filter.
+func (f *CuckooFilter) TestString(key string) bool {
+	return f.Test([]byte(key))
+}
+
+// Remove removes one occurrence of the key from the filter and reports whether it
+// was found. Only keys that were added should be removed, otherwise the fingerprint
+// of a different key may be removed instead.
+func (f *CuckooFilter) Remove(key []byte) bool {
+	if len(f.buckets) == 0 {
+		return false
+	}
+
+	fp, i1, i2 := f.indexes(key)
+	for _, i := range []uint64{i1, i2} {
+		for slot := range f.buckets[i] {
+			if f.buckets[i][slot] == fp {
+				f.buckets[i][slot] = 0
+				f.count--
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoveString removes one occurrence of the string key and reports whether it was found.
+func (f *CuckooFilter) RemoveString(key string) bool {
+	return f.Remove([]byte(key))
+}
+
+// Len returns the number of keys stored in the filter.
+func (f *CuckooFilter) Len() int {
+	return int(f.count)
+}
+
+// Reset removes every key from the filter, keeping its size.
+func (f *CuckooFilter) Reset() {
+	f.buckets = make([]cuckooBucket, len(f.buckets))
+	f.count = 0
+}
+
+// MarshalBinary encodes the filter so that it can be persisted and restored
+// with UnmarshalBinary.
+func (f *CuckooFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 16+2*cuckooBucketSize*len(f.buckets))
+	binary.BigEndian.PutUint64(data[0:], uint64(len(f.buckets)))
+	binary.BigEndian.PutUint64(data[8:], f.count)
+
+	offset := 16
+	for i := range f.buckets {
+		for _, fp := range f.buckets[i] {
+			binary.BigEndian.PutUint16(data[offset:], fp)
+			offset += 2
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary replaces the filter with one decoded from data produced by MarshalBinary.
+func (f *CuckooFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("cuckoo filter data is too short [length=%d]", len(data))
+	}
+
+	n := binary.BigEndian.Uint64(data[0:])
+	count := binary.BigEndian.Uint64(data[8:])
+	// Compare the bucket count before multiplying, so that a huge count cannot overflow.
+	size := uint64(len(data) - 16)
+	if n == 0 || n&(n-1) != 0 || n > size/(2*cuckooBucketSize) || size != 2*cuckooBucketSize*n {
+		return fmt.Errorf("cuckoo filter data is malformed [length=%d] [buckets=%d]", len(data), n)
+	}
+
+	f.buckets = make([]cuckooBucket, n)
+	f.count = count
+
+	offset := 16
+	for i := range f.buckets {
+		for slot := range f.buckets[i] {
+			f.buckets[i][slot] = binary.BigEndian.Uint16(data[offset:])
+			offset += 2
+		}
+	}
+
+	return nil
+}
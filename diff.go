@@ -0,0 +1,140 @@
+package zdutil
+
+// SetDiff describes how a set changed between two snapshots.
+type SetDiff[T comparable] struct {
+	Added     *Set[T]
+	Removed   *Set[T]
+	Unchanged *Set[T]
+}
+
+// Diff compares two snapshots of a set. Added holds the elements only in after,
+// Removed the elements only in before, and Unchanged the elements in both.
+func Diff[T comparable](before, after *Set[T]) SetDiff[T] {
+	diff := SetDiff[T]{
+		Added:     NewSet[T](),
+		Removed:   NewSet[T](),
+		Unchanged: NewSet[T](),
+	}
+
+	for elem := range before.elements {
+		if _, ok := after.elements[elem]; ok {
+			diff.Unchanged.elements[elem] = struct{}{}
+		} else {
+			diff.Removed.elements[elem] = struct{}{}
+		}
+	}
+	for elem := range after.elements {
+		if _, ok := before.elements[elem]; !ok {
+			diff.Added.elements[elem] = struct{}{}
+		}
+	}
+
+	return diff
+}
+
+// SliceDiff describes how a slice of distinct elements changed between two snapshots.
+type SliceDiff[T any] struct {
+	Added     []T
+	Removed   []T
+	Unchanged []T
+}
+
+// DiffSlices compares two snapshots of a slice as sets. Added and Unchanged are in
+// the order the elements first appear in after, and Removed in the order they first
+// appear in before. Duplicates are ignored.
+func DiffSlices[T comparable](before, after []T) SliceDiff[T] {
+	var diff SliceDiff[T]
+
+	inBefore := make(map[T]bool, len(before))
+	for i := range before {
+		inBefore[before[i]] = false
+	}
+
+	for i := range after {
+		seen, ok := inBefore[after[i]]
+		switch {
+		case !ok:
+			inBefore[after[i]] = true
+			diff.Added = append(diff.Added, after[i])
+		case !seen:
+			inBefore[after[i]] = true
+			diff.Unchanged = append(diff.Unchanged, after[i])
+		}
+	}
+
+	for i := range before {
+		if !inBefore[before[i]] {
+			inBefore[before[i]] = true
+			diff.Removed = append(diff.Removed, before[i])
+		}
+	}
+
+	return diff
+}
+
+// Change pairs the old and new version of an element that was matched by key.
+type Change[T any] struct {
+	Old T
+	New T
+}
+
+// KeyedDiff describes how a slice of keyed elements changed between two snapshots.
+type KeyedDiff[T any] struct {
+	Added     []T
+	Removed   []T
+	Unchanged []T
+	Changed   []Change[T]
+}
+
+// DiffBy compares two snapshots of a slice whose elements are identified by key.
+// Elements with the same key in both snapshots are compared with equal and reported
+// as Unchanged or Changed. Added, Unchanged and Changed are in the order of after,
+// and Removed in the order of before. When a key occurs more than once in a snapshot
+// only its first occurrence is used.
+func DiffBy[T any, K comparable](before, after []T, key func(T) K, equal func(a, b T) bool) KeyedDiff[T] {
+	var diff KeyedDiff[T]
+
+	type entry struct {
+		value   T
+		matched bool
+	}
+
+	byKey := make(map[K]*entry, len(before))
+	for i := range before {
+		k := key(before[i])
+		if _, ok := byKey[k]; !ok {
+			byKey[k] = &entry{value: before[i]}
+		}
+	}
+
+	seen := make(map[K]struct{}, len(after))
+	for i := range after {
+		k := key(after[i])
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+
+		old, ok := byKey[k]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, after[i])
+		case equal(old.value, after[i]):
+			old.matched = true
+			diff.Unchanged = append(diff.Unchanged, after[i])
+		default:
+			old.matched = true
+			diff.Changed = append(diff.Changed, Change[T]{Old: old.value, New: after[i]})
+		}
+	}
+
+	for i := range before {
+		old := byKey[key(before[i])]
+		if !old.matched {
+			old.matched = true
+			diff.Removed = append(diff.Removed, old.value)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,39 @@
+package zdutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	diff := Diff(NewSet(1, 2, 3), NewSet(2, 3, 4))
+	if !diff.Added.Equal(*NewSet(4)) || !diff.Removed.Equal(*NewSet(1)) || !diff.Unchanged.Equal(*NewSet(2, 3)) {
+		fmt.Printf("[ERROR] failed to diff sets:\n\t[added=%v]\n\t[removed=%v]\n\t[unchanged=%v]\n",
+			diff.Added.Values(), diff.Removed.Values(), diff.Unchanged.Values())
+		t.FailNow()
+	}
+
+	sliceDiff := DiffSlices([]string{"a", "b", "b", "c"}, []string{"d", "c", "a", "d"})
+	got := fmt.Sprint(sliceDiff.Added, sliceDiff.Removed, sliceDiff.Unchanged)
+	want := "[d] [b] [c a]"
+	if got != want {
+		fmt.Printf("[ERROR] failed to diff slices:\n\t[got=%s]\n\t[want=%s]\n", got, want)
+		t.FailNow()
+	}
+
+	type item struct {
+		ID    int
+		Value string
+	}
+
+	before := []item{{1, "a"}, {2, "b"}, {3, "c"}}
+	after := []item{{2, "b"}, {3, "C"}, {4, "d"}}
+	keyed := DiffBy(before, after, func(i item) int { return i.ID }, func(a, b item) bool { return a == b })
+
+	got = fmt.Sprint(keyed.Added, keyed.Removed, keyed.Unchanged, keyed.Changed)
+	want = "[{4 d}] [{1 a}] [{2 b}] [{{3 c} {3 C}}]"
+	if got != want {
+		fmt.Printf("[ERROR] failed to diff keyed slices:\n\t[got=%s]\n\t[want=%s]\n", got, want)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,88 @@
+package zdutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// sortValues sorts the given slice in place when the underlying kind of T is
+// ordered (integers, floats and strings). Slices of any other kind are left as is.
+func sortValues[T any](values []T) {
+	if len(values) < 2 {
+		return
+	}
+
+	rv := reflect.ValueOf(values)
+	switch rv.Index(0).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sort.Slice(values, func(i, j int) bool { return rv.Index(i).Int() < rv.Index(j).Int() })
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sort.Slice(values, func(i, j int) bool { return rv.Index(i).Uint() < rv.Index(j).Uint() })
+	case reflect.Float32, reflect.Float64:
+		sort.Slice(values, func(i, j int) bool { return rv.Index(i).Float() < rv.Index(j).Float() })
+	case reflect.String:
+		sort.Slice(values, func(i, j int) bool { return rv.Index(i).String() < rv.Index(j).String() })
+	}
+}
+
+// marshalElemText returns the text form of a single element. Elements that
+// implement encoding.TextMarshaler are marshaled with it, strings, booleans
+// and numbers are formatted with fmt, and every other type returns an error.
+func marshalElemText(v interface{}) ([]byte, error) {
+	if tm, ok := v.(encoding.TextMarshaler); ok {
+		return tm.MarshalText()
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return []byte(fmt.Sprint(v)), nil
+	}
+
+	return nil, fmt.Errorf("type %T does not support text marshaling", v)
+}
+
+// marshalTextValues formats values as "[x,y,z]", the same layout used by Stack.String.
+func marshalTextValues[T any](values []T) ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteByte('[')
+	for i := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		text, err := marshalElemText(values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(text)
+	}
+	b.WriteByte(']')
+
+	return b.Bytes(), nil
+}
+
+// gobEncodeValues encodes values as a gob stream.
+func gobEncodeValues[T any](values []T) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(values); err != nil {
+		return nil, fmt.Errorf("failed to gob encode values: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// gobDecodeValues decodes a gob stream produced by gobEncodeValues.
+func gobDecodeValues[T any](data []byte) ([]T, error) {
+	var values []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return nil, fmt.Errorf("failed to gob decode values: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,281 @@
+package zdutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock reports the current time. It allows tests to control time deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type ExpiringSetOpt struct {
+	Clock Clock
+}
+
+type ExpiringSetOption func(*ExpiringSetOpt)
+
+// ExpiringClockOpt returns an ExpiringSetOption that sets the clock used to
+// compute and check expiry times. This overrides the default of the system clock.
+func ExpiringClockOpt(c Clock) ExpiringSetOption {
+	return func(eo *ExpiringSetOpt) {
+		eo.Clock = c
+	}
+}
+
+// ExpiringSet is a set where every element expires after its own time to live.
+// Expired elements are removed lazily when they are accessed, which keeps lookups
+// O(1), and all at once by Purge or by a janitor started with StartJanitor. Len,
+// Values and Each skip expired elements without removing them. It is safe for
+// concurrent use.
+type ExpiringSet[T comparable] struct {
+	expires  map[T]time.Time
+	clock    Clock
+	onExpire []func(T)
+	m        sync.Mutex
+}
+
+// NewExpiringSet creates an empty ExpiringSet.
+func NewExpiringSet[T comparable](opts ...ExpiringSetOption) *ExpiringSet[T] {
+	opt := &ExpiringSetOpt{
+		Clock: systemClock{},
+	}
+
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &ExpiringSet[T]{
+		expires: make(map[T]time.Time),
+		clock:   opt.Clock,
+	}
+}
+
+// OnExpire registers a callback that is called with every element that expires.
+// Callbacks are called without holding the lock, so they may safely call back into the set.
+// Elements removed with Remove or Clear are not reported.
+func (s *ExpiringSet[T]) OnExpire(fn func(T)) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.onExpire = append(s.onExpire, fn)
+}
+
+// Add adds the specified element to the set with the given time to live.
+// If the element already exists its time to live is replaced.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Add(element T, ttl time.Duration) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.expires[element] = s.clock.Now().Add(ttl)
+}
+
+// Touch resets the time to live of the element and reports whether it exists.
+// Expired elements are removed first, so they are not revived.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Touch(element T, ttl time.Duration) bool {
+	s.m.Lock()
+	expired := s.expire(element)
+	_, ok := s.expires[element]
+	if ok {
+		s.expires[element] = s.clock.Now().Add(ttl)
+	}
+	s.m.Unlock()
+
+	s.notify(expired)
+	return ok
+}
+
+// TTL returns the remaining time to live of the element and true,
+// or zero and false if the element does not exist or has expired.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) TTL(element T) (time.Duration, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	expires, ok := s.expires[element]
+	if !ok {
+		return 0, false
+	}
+
+	ttl := expires.Sub(s.clock.Now())
+	if ttl <= 0 {
+		return 0, false
+	}
+	return ttl, true
+}
+
+// Remove deletes the specified element from the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Remove(element T) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	delete(s.expires, element)
+}
+
+// Contains checks if the set contains all of the given unexpired elements, following
+// the same rules as Set.Contains. The given elements that have expired are removed.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Contains(elems ...T) bool {
+	s.m.Lock()
+	var expired []T
+	for i := range elems {
+		expired = append(expired, s.expire(elems[i])...)
+	}
+
+	ok := len(elems) > 0 || s.len() == 0
+	for i := range elems {
+		if _, found := s.expires[elems[i]]; !found {
+			ok = false
+			break
+		}
+	}
+	s.m.Unlock()
+
+	s.notify(expired)
+	return ok
+}
+
+// Len returns the number of unexpired elements in the set. This counts every element,
+// so it takes time proportional to the size of the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Len() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.len()
+}
+
+// Clear removes all elements from the set without reporting them as expired.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Clear() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.expires = make(map[T]time.Time)
+}
+
+// Values returns all unexpired elements in the set.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) Values() []T {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := s.clock.Now()
+	values := make([]T, 0, len(s.expires))
+	for elem, expires := range s.expires {
+		if now.Before(expires) {
+			values = append(values, elem)
+		}
+	}
+	return values
+}
+
+// Each calls fn for every unexpired element in the set, in unspecified order, until
+// fn returns false. It iterates over a copy, so fn may safely modify the set.
+func (s *ExpiringSet[T]) Each(fn func(T) bool) {
+	for _, elem := range s.Values() {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// Purge removes every expired element, reports them to the OnExpire callbacks
+// and returns how many were removed.
+func (s *ExpiringSet[T]) Purge() int {
+	expired := s.purge()
+	s.notify(expired)
+	return len(expired)
+}
+
+// StartJanitor starts a goroutine that calls Purge every interval,
+// until the context is cancelled. It does nothing if interval is not positive.
+func (s *ExpiringSet[T]) StartJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Purge()
+			}
+		}
+	}()
+}
+
+// purge removes every expired element and returns them.
+// It acquires a lock to ensure thread-safe access.
+func (s *ExpiringSet[T]) purge() []T {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var expired []T
+	now := s.clock.Now()
+	for elem, expires := range s.expires {
+		if !now.Before(expires) {
+			delete(s.expires, elem)
+			expired = append(expired, elem)
+		}
+	}
+
+	return expired
+}
+
+// expire removes element if it has expired and returns it, or returns nil.
+// The caller must hold the lock.
+func (s *ExpiringSet[T]) expire(element T) []T {
+	expires, ok := s.expires[element]
+	if !ok || s.clock.Now().Before(expires) {
+		return nil
+	}
+
+	delete(s.expires, element)
+	return []T{element}
+}
+
+// len returns the number of unexpired elements. The caller must hold the lock.
+func (s *ExpiringSet[T]) len() int {
+	now := s.clock.Now()
+	n := 0
+	for _, expires := range s.expires {
+		if now.Before(expires) {
+			n++
+		}
+	}
+	return n
+}
+
+// notify calls the OnExpire callbacks with every expired element.
+func (s *ExpiringSet[T]) notify(expired []T) {
+	if len(expired) == 0 {
+		return
+	}
+
+	s.m.Lock()
+	callbacks := s.onExpire
+	s.m.Unlock()
+
+	for _, elem := range expired {
+		for _, fn := range callbacks {
+			fn(elem)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package zdutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+	m   sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestExpiringSet(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	set := NewExpiringSet[string](ExpiringClockOpt(clock))
+
+	var expired []string
+	set.OnExpire(func(elem string) {
+		expired = append(expired, elem)
+	})
+
+	set.Add("a", time.Minute)
+	set.Add("b", 2*time.Minute)
+	set.Add("c", 3*time.Minute)
+
+	clock.Advance(90 * time.Second)
+	if set.Contains("a") || !set.Contains("b", "c") || set.Len() != 2 {
+		fmt.Printf("[ERROR] failed to expire element:\n\t[got=%v]\n", set.Values())
+		t.FailNow()
+	}
+
+	if !set.Touch("b", 5*time.Minute) || set.Touch("a", time.Minute) {
+		fmt.Println("[ERROR] expected touch to extend only existing elements")
+		t.FailNow()
+	}
+
+	clock.Advance(2 * time.Minute)
+	if !set.Contains("b") || set.Contains("c") {
+		fmt.Printf("[ERROR] failed to extend ttl:\n\t[got=%v]\n", set.Values())
+		t.FailNow()
+	}
+
+	if ttl, ok := set.TTL("b"); !ok || ttl != 3*time.Minute {
+		fmt.Printf("[ERROR] failed to get remaining ttl:\n\t[got=%s]\n\t[want=%s]\n", ttl, 3*time.Minute)
+		t.FailNow()
+	}
+
+	want := []string{"a", "c"}
+	if fmt.Sprint(expired) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to report expired elements:\n\t[got=%v]\n\t[want=%v]\n", expired, want)
+		t.FailNow()
+	}
+}
+
+func TestExpiringSetJanitor(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	set := NewExpiringSet[int](ExpiringClockOpt(clock))
+
+	expired := make(chan int, 1)
+	set.OnExpire(func(elem int) {
+		expired <- elem
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	set.Add(1, time.Second)
+	set.StartJanitor(ctx, time.Millisecond)
+	clock.Advance(time.Second)
+
+	select {
+	case elem := <-expired:
+		if elem != 1 {
+			fmt.Printf("[ERROR] janitor expired wrong element:\n\t[got=%d]\n\t[want=%d]\n", elem, 1)
+			t.FailNow()
+		}
+	case <-time.After(5 * time.Second):
+		fmt.Println("[ERROR] janitor did not expire element")
+		t.FailNow()
+	}
+}
+
+func TestExpiringSetLazy(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	set := NewExpiringSet[int](ExpiringClockOpt(clock))
+
+	var expired []int
+	set.OnExpire(func(elem int) {
+		expired = append(expired, elem)
+	})
+
+	set.Add(1, time.Second)
+	set.Add(2, time.Second)
+	set.Add(3, time.Minute)
+	clock.Advance(time.Second)
+
+	if set.Contains(1) || set.Len() != 1 || len(set.Values()) != 1 || fmt.Sprint(expired) != "[1]" {
+		fmt.Printf("[ERROR] expected only accessed elements to be removed:\n\t[expired=%v]\n", expired)
+		t.FailNow()
+	}
+
+	if set.Touch(2, time.Minute) || set.Purge() != 0 || fmt.Sprint(expired) != "[1 2]" {
+		fmt.Printf("[ERROR] expected touch to remove expired element:\n\t[expired=%v]\n", expired)
+		t.FailNow()
+	}
+
+	// a janitor with a non-positive interval is not started
+	set.StartJanitor(context.Background(), 0)
+}
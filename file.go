@@ -1,6 +1,8 @@
 package zdutil
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -34,102 +36,728 @@ func FileExists(filename string) bool {
 	return !info.IsDir()
 }
 
-// RenameFile renames a file from `src` to `dst`. If the renaming fails during any part of the process
-// (opening the source file, creating the destination file, copying the contents of the source file to
-// the destination, or removing the original file), an error is returned with the first error encountered.
-// Note that the destination file is closed after the copy is complete, but the source file is only closed
-// if the copy is successful. If the copy fails, the source file is left open to allow the caller to
-// inspect the contents of the source file.
+// Preserve selects the file metadata that is kept when a move has to fall back to copying.
+type Preserve uint8
+
+const (
+	// PreserveMode keeps the permission bits, including setuid, setgid and sticky.
+	PreserveMode Preserve = 1 << iota
+	// PreserveTimes keeps the access and modification times. On platforms that do not
+	// expose access times, such as windows, the modification time is used for both.
+	PreserveTimes
+	// PreserveOwner keeps the uid and gid where the process is permitted to set them.
+	PreserveOwner
+
+	PreserveNone Preserve = 0
+	PreserveAll           = PreserveMode | PreserveTimes | PreserveOwner
+)
+
+// ConflictPolicy decides what happens when a file is moved onto a path that already exists.
+// Whenever the existing destination is kept, the source file is left in place. See
+// RenameDirWithOpts for how it applies to directories.
+type ConflictPolicy uint8
+
+const (
+	// ConflictOverwrite replaces the destination with the source.
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictSkip keeps the destination.
+	ConflictSkip
+	// ConflictError stops the move with an error wrapping fs.ErrExist.
+	ConflictError
+	// ConflictRenameWithSuffix moves the source next to the destination, adding the
+	// first free numbered suffix before the extension, as in "file (1).txt".
+	ConflictRenameWithSuffix
+	// ConflictKeepNewer keeps whichever file has the later modification time,
+	// preferring the destination when they are equal.
+	ConflictKeepNewer
+	// ConflictKeepLarger keeps whichever file is larger, preferring the destination
+	// when they are the same size.
+	ConflictKeepLarger
+)
+
+// ConflictResolver chooses the policy for a single conflict, given the source and the
+// existing destination. It may return any policy, and returning an error stops the move.
+type ConflictResolver func(src, dst string, srcInfo, dstInfo os.FileInfo) (ConflictPolicy, error)
+
+type RenameOpt struct {
+	Preserve      Preserve
+	Conflict      ConflictPolicy
+	Resolver      ConflictResolver
+	IgnoreExists  *PathMatcher
+	Include       *PathMatcher
+	Exclude       *PathMatcher
+	Transactional bool
+	Journal       string
+}
+
+type RenameOption func(*RenameOpt)
+
+// RenamePreserveOpt returns a RenameOption that sets which metadata is kept when
+// files and directories are copied. This overrides the default of PreserveAll.
+func RenamePreserveOpt(p Preserve) RenameOption {
+	return func(ro *RenameOpt) {
+		ro.Preserve = p
+	}
+}
+
+// RenameConflictOpt returns a RenameOption that sets how existing destination files
+// are handled. This overrides the default of ConflictOverwrite.
+func RenameConflictOpt(p ConflictPolicy) RenameOption {
+	return func(ro *RenameOpt) {
+		ro.Conflict = p
+	}
+}
+
+// RenameResolverOpt returns a RenameOption that sets a resolver which chooses the
+// policy for every conflict, taking precedence over RenameConflictOpt.
+func RenameResolverOpt(fn ConflictResolver) RenameOption {
+	return func(ro *RenameOpt) {
+		ro.Resolver = fn
+	}
+}
+
+// RenameIgnoreExistsOpt returns a RenameOption that skips moving files whose
+// destination already exists and whose path relative to the source matches one of
+// the gitignore-style patterns, regardless of the conflict policy. See PathMatcher.
+// Malformed patterns are compared literally.
+func RenameIgnoreExistsOpt(patterns ...string) RenameOption {
+	return func(ro *RenameOpt) {
+		if ro.IgnoreExists == nil {
+			ro.IgnoreExists = &PathMatcher{}
+		}
+		ro.IgnoreExists.add(patterns, false)
+	}
+}
+
+// RenameIncludeOpt returns a RenameOption that only moves the files whose path
+// relative to the source is matched by m. Directories are always traversed.
+func RenameIncludeOpt(m *PathMatcher) RenameOption {
+	return func(ro *RenameOpt) {
+		ro.Include = m
+	}
+}
+
+// RenameExcludeOpt returns a RenameOption that leaves the files and directories whose
+// path relative to the source is matched by m in place. It takes precedence over
+// RenameIncludeOpt.
+func RenameExcludeOpt(m *PathMatcher) RenameOption {
+	return func(ro *RenameOpt) {
+		ro.Exclude = m
+	}
+}
+
+// filtered reports whether any include or exclude filter is set.
+func (ro *RenameOpt) filtered() bool {
+	return ro.Include != nil || ro.Exclude != nil
+}
+
+// included reports whether the path rel, relative to the source, should be moved.
+func (ro *RenameOpt) included(rel string, isDir bool) bool {
+	if ro.Exclude.Match(rel, isDir) {
+		return false
+	}
+	return isDir || ro.Include == nil || ro.Include.Match(rel, false)
+}
+
+// RenameTransactionalOpt returns a RenameOption that makes directory moves all or
+// nothing. Every file is copied and verified before any source is removed, and a
+// failure removes everything that was created. If journal is not empty, the progress
+// is recorded in that file so that an interrupted move can be finished with
+// ResumeRename or undone with RevertRename.
+func RenameTransactionalOpt(journal string) RenameOption {
+	return func(ro *RenameOpt) {
+		ro.Transactional = true
+		ro.Journal = journal
+	}
+}
+
+func newRenameOpt(opts []RenameOption) *RenameOpt {
+	opt := &RenameOpt{
+		Preserve: PreserveAll,
+		Conflict: ConflictOverwrite,
+	}
+
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return opt
+}
+
+// resolve applies the conflict policy to moving the file src, at rel relative to the
+// source root, onto dst. It returns the path the file should be moved to, or false if
+// the file should be left in place. Suffixed names in reserved are never chosen, so
+// that a planned move does not pick a name another file is already moving to.
+func (ro *RenameOpt) resolve(rel, src, dst string, srcInfo os.FileInfo, reserved map[string]bool) (string, bool, error) {
+	dstInfo, err := os.Stat(dst)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dst, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if dstInfo.IsDir() {
+		return "", false, fmt.Errorf("destination is a directory [path=%s]: %w", dst, fs.ErrExist)
+	}
+
+	if ro.IgnoreExists.Match(rel, false) {
+		return "", false, nil
+	}
+
+	policy := ro.Conflict
+	if ro.Resolver != nil {
+		policy, err = ro.Resolver(src, dst, srcInfo, dstInfo)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	switch policy {
+	case ConflictOverwrite:
+		return dst, true, nil
+	case ConflictSkip:
+		return "", false, nil
+	case ConflictError:
+		return "", false, fmt.Errorf("destination already exists [path=%s]: %w", dst, fs.ErrExist)
+	case ConflictRenameWithSuffix:
+		free, err := suffixedPath(dst, false, reserved)
+		return free, err == nil, err
+	case ConflictKeepNewer:
+		return dst, srcInfo.ModTime().After(dstInfo.ModTime()), nil
+	case ConflictKeepLarger:
+		return dst, srcInfo.Size() > dstInfo.Size(), nil
+	}
+
+	return "", false, fmt.Errorf("unknown conflict policy [policy=%d]", policy)
+}
+
+// resolveDir applies the conflict policy to moving the directory src onto an existing
+// dst, as described in RenameDirWithOpts. It returns the directory to move src to, or
+// false if nothing should be moved.
+func (ro *RenameOpt) resolveDir(src, dst string) (string, bool, error) {
+	dstInfo, err := os.Stat(dst)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dst, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if !dstInfo.IsDir() {
+		return "", false, fmt.Errorf("destination is not a directory [path=%s]: %w", dst, fs.ErrExist)
+	}
+
+	policy := ro.Conflict
+	if ro.Resolver != nil {
+		srcInfo, err := os.Stat(src)
+		if err != nil {
+			return "", false, err
+		}
+
+		policy, err = ro.Resolver(src, dst, srcInfo, dstInfo)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	switch policy {
+	case ConflictOverwrite, ConflictKeepNewer, ConflictKeepLarger:
+		return dst, true, nil
+	case ConflictSkip:
+		return "", false, nil
+	case ConflictError:
+		return "", false, fmt.Errorf("destination already exists [path=%s]: %w", dst, fs.ErrExist)
+	case ConflictRenameWithSuffix:
+		free, err := suffixedPath(dst, true, nil)
+		return free, err == nil, err
+	}
+
+	return "", false, fmt.Errorf("unknown conflict policy [policy=%d]", policy)
+}
+
+// suffixedPath returns the first path of the form "name (n).ext" that does not exist
+// and is not in reserved, counting n up from 1. Directories have no extension.
+func suffixedPath(p string, isDir bool, reserved map[string]bool) (string, error) {
+	ext := filepath.Ext(p)
+	if isDir || ext == filepath.Base(p) {
+		ext = ""
+	}
+	base := p[:len(p)-len(ext)]
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if reserved[filepath.Clean(candidate)] {
+			continue
+		}
+
+		_, err := os.Lstat(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// RenameFile renames a file from `src` to `dst` by copying it and removing the original,
+// keeping all of its metadata. See RenameFileWithOpts.
 func RenameFile(src, dst string) error {
+	return RenameFileWithOpts(src, dst)
+}
+
+// RenameFileWithOpts renames a file from `src` to `dst` by copying its contents and
+// then removing the original, which also works across devices. An existing `dst` is
+// handled by the conflict policy and the metadata selected with RenamePreserveOpt is
+// copied. If any step fails the first error encountered is returned and the original
+// file is kept.
+func RenameFileWithOpts(src, dst string, opts ...RenameOption) error {
+	return moveFile(path.Base(src), src, dst, newRenameOpt(opts), false)
+}
+
+// moveFile moves the file src, at rel relative to the source root, to dst after
+// applying the filters and the conflict policy. If rename is true it tries os.Rename
+// before copying.
+func moveFile(rel, src, dst string, opt *RenameOpt, rename bool) error {
+	if !opt.included(rel, false) {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	target, ok, err := opt.resolve(rel, src, dst, info, nil)
+	if !ok {
+		return err
+	}
+
+	if rename && os.Rename(src, target) == nil {
+		return nil
+	}
+
+	return renameFile(src, target, opt)
+}
+
+// renameFile copies src to dst with the selected metadata and removes src.
+func renameFile(src, dst string, opt *RenameOpt) error {
+	if err := copyFile(src, dst, opt.Preserve, false); err != nil {
+		return err
+	}
+
+	// The copy was successful, so now delete the original file
+	err := os.Remove(src)
+	if err != nil {
+		return fmt.Errorf("failed removing original file: %s", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst with the metadata selected by p. If verify is true
+// the written file is read back and its checksum compared with that of src.
+func copyFile(src, dst string, p Preserve, verify bool) error {
 	inputFile, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %s", err)
 	}
-	outputFile, err := os.Create(dst)
+	defer inputFile.Close()
+
+	info, err := inputFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %s", err)
+	}
+
+	perm := os.FileMode(0666)
+	if p&PreserveMode != 0 {
+		perm = info.Mode().Perm()
+	}
+
+	outputFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
-		inputFile.Close()
 		return fmt.Errorf("failed to open dest file: %s", err)
 	}
-	defer outputFile.Close()
-	_, err = io.Copy(outputFile, inputFile)
-	inputFile.Close()
+
+	var w io.Writer = outputFile
+	sum := sha256.New()
+	if verify {
+		w = io.MultiWriter(outputFile, sum)
+	}
+
+	_, err = io.Copy(w, inputFile)
+	if cerr := outputFile.Close(); err == nil {
+		err = cerr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write to output file: %s", err)
 	}
-	// The copy was successful, so now delete the original file
-	err = os.Remove(src)
+
+	if verify {
+		written, err := fileChecksum(dst)
+		if err != nil {
+			return fmt.Errorf("failed to verify output file: %s", err)
+		}
+		if !bytes.Equal(written, sum.Sum(nil)) {
+			return fmt.Errorf("output file does not match source file [src=%s] [dst=%s]", src, dst)
+		}
+	}
+
+	if err := copyMetadata(dst, info, p); err != nil {
+		return fmt.Errorf("failed to copy file metadata: %s", err)
+	}
+
+	return nil
+}
+
+// fileChecksum returns the SHA-256 checksum of the contents of the file at path.
+func fileChecksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed removing original file: %s", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return nil, err
+	}
+	return sum.Sum(nil), nil
+}
+
+// copyMetadata applies the metadata selected by p from info to the file at path.
+// Ownership is changed first, since chown clears the setuid and setgid bits.
+// Failing to change the owner for lack of permission is not an error.
+func copyMetadata(path string, info os.FileInfo, p Preserve) error {
+	if p&PreserveOwner != 0 {
+		if uid, gid, ok := fileOwner(info); ok {
+			err := os.Chown(path, uid, gid)
+			if err != nil && !errors.Is(err, fs.ErrPermission) {
+				return err
+			}
+		}
+	}
+
+	if p&PreserveMode != 0 {
+		err := os.Chmod(path, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky))
+		if err != nil {
+			return err
+		}
 	}
+
+	if p&PreserveTimes != 0 {
+		err := os.Chtimes(path, fileAtime(info), info.ModTime())
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// dirPerm returns the permissions to create a copy of the directory described by info
+// with. The owner can always write to it, so that its contents can be moved in before
+// copyMetadata applies the final mode.
+func dirPerm(info os.FileInfo, p Preserve) os.FileMode {
+	if p&PreserveMode != 0 {
+		return info.Mode().Perm() | 0700
+	}
+	return 0755
+}
+
 // RenameDir renames a directory from `src` to `dst`, recursively renaming all of the files and
-// subdirectories inside of `src`. If any of the files or directories inside of `src` have the same
-// name as one of the strings in `ignoreExists`, they are skipped and not renamed.
+// subdirectories inside of `src`. If any of the files inside of `src` already exist in `dst` and
+// match one of the gitignore-style patterns in `ignoreExists`, they are skipped and not renamed.
 func RenameDir(src, dst string, ignoreExists ...string) error {
-	os.Mkdir(dst, 0644)
-	err := filepath.WalkDir(src, func(path string, info fs.DirEntry, err error) error {
+	return RenameDirWithOpts(src, dst, RenameIgnoreExistsOpt(ignoreExists...))
+}
+
+// RenameDirWithOpts renames a directory from `src` to `dst`, recursively renaming all of
+// the files inside of `src` with RenameFileWithOpts. Excluded directories are skipped
+// entirely.
+//
+// If `dst` already exists the conflict policy is first applied to the directory as a
+// whole: ConflictSkip moves nothing, ConflictError fails before anything is moved and
+// ConflictRenameWithSuffix moves `src` to "dst (n)" instead. ConflictOverwrite,
+// ConflictKeepNewer and ConflictKeepLarger merge `src` into `dst` and apply the policy
+// to every file that already exists. A resolver is asked once for the directory and,
+// if it merges, again for every conflicting file.
+//
+// Directories are created with the metadata selected by
+// RenamePreserveOpt, which is applied once their contents are in place so that read-only
+// directories can still be filled and their times are kept.
+func RenameDirWithOpts(src, dst string, opts ...RenameOption) error {
+	opt := newRenameOpt(opts)
+
+	target, ok, err := opt.resolveDir(src, dst)
+	if !ok {
+		return err
+	}
+
+	return renameDir(src, target, opt)
+}
+
+// renameDir moves the directory src into dst, merging it with any existing directory.
+func renameDir(src, dst string, opt *RenameOpt) error {
+	if opt.Transactional {
+		return renameDirTx(src, dst, opt)
+	}
+
+	type createdDir struct {
+		path string
+		info os.FileInfo
+	}
+	var created []createdDir
+
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
 		newDst := dst + "/" + path[len(src):]
-		if info.IsDir() {
-			err = os.Mkdir(newDst, 0644)
+		if path != src && !opt.included(path[len(src):], entry.IsDir()) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
 
-			if err != nil && !errors.Is(err, fs.ErrExist) {
+			err = os.Mkdir(newDst, dirPerm(info, opt.Preserve))
+			if err == nil {
+				created = append(created, createdDir{path: newDst, info: info})
+			} else if !errors.Is(err, fs.ErrExist) || !FolderExists(newDst) {
 				return err
 			}
 			return nil
 		}
 
-		if FileExists(newDst) { // check if file should be ignored
-			for _, ignore := range ignoreExists {
-				if ignore == path[len(src):] {
-					return nil
+		return moveFile(path[len(src):], path, newDst, opt, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Children come after their parents in walk order, so apply the metadata in
+	// reverse to avoid touching a directory's times after they have been set.
+	for i := len(created) - 1; i >= 0; i-- {
+		// With an include filter, directories without any included file are not kept.
+		if opt.Include != nil {
+			if entries, err := os.ReadDir(created[i].path); err == nil && len(entries) == 0 {
+				if err := os.Remove(created[i].path); err != nil {
+					return err
 				}
+				continue
 			}
 		}
 
-		err = RenameFile(path, newDst)
-		if err != nil {
-			return err
+		if err := copyMetadata(created[i].path, created[i].info, opt.Preserve); err != nil {
+			return fmt.Errorf("failed to copy directory metadata: %s", err)
 		}
+	}
 
-		return nil
-	})
-
-	return err
+	return nil
 }
 
 // Rename attempts to rename a file or directory from `src` to `dst`.
 // If `src` is a directory, it calls RenameDir to handle recursive renaming.
-// If `src` is a file and the destination already exists with a name found
-// in `ignoreExists`, the operation is skipped.
+// If `src` is a file and the destination already exists with a name matched
+// by the gitignore-style patterns in `ignoreExists`, the operation is skipped.
 // Returns an error if the operation fails at any point.
 func Rename(src, dst string, ignoreExists ...string) error {
-	err := os.Rename(src, dst)
-	if err == nil {
-		return nil
-	}
+	return RenameWithOpts(src, dst, RenameIgnoreExistsOpt(ignoreExists...))
+}
 
+// RenameWithOpts attempts to rename a file or directory from `src` to `dst` with
+// os.Rename, falling back to RenameDirWithOpts or RenameFileWithOpts when that
+// fails, for example because `src` and `dst` are on different devices. If `src` is
+// a file that conflicts with an existing `dst`, the conflict policy is applied first.
+// If `src` is a directory that conflicts with an existing `dst`, the policy is applied
+// as described in RenameDirWithOpts.
+func RenameWithOpts(src, dst string, opts ...RenameOption) error {
 	info, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
 
+	opt := newRenameOpt(opts)
 	if info.IsDir() {
-		return RenameDir(src, dst, ignoreExists...)
+		target, ok, err := opt.resolveDir(src, dst)
+		if !ok {
+			return err
+		}
+
+		// A filtered move has to visit every file, so it cannot rename the whole directory.
+		if !opt.filtered() && os.Rename(src, target) == nil {
+			return nil
+		}
+		return renameDir(src, target, opt)
+	}
+
+	return moveFile(path.Base(src), src, dst, opt, true)
+}
+
+type AtomicWriteOpt struct {
+	PreserveMode  bool
+	PreserveOwner bool
+}
+
+type AtomicWriteOption func(*AtomicWriteOpt)
+
+// AtomicPreserveModeOpt returns an AtomicWriteOption that keeps the permission bits
+// of the file being replaced instead of using the given perm. It has no effect when
+// the file does not exist yet.
+func AtomicPreserveModeOpt() AtomicWriteOption {
+	return func(ao *AtomicWriteOpt) {
+		ao.PreserveMode = true
+	}
+}
+
+// AtomicPreserveOwnerOpt returns an AtomicWriteOption that keeps the owner and group
+// of the file being replaced. Changing the owner usually requires elevated privileges
+// and is not supported on windows. It has no effect when the file does not exist yet.
+func AtomicPreserveOwnerOpt() AtomicWriteOption {
+	return func(ao *AtomicWriteOpt) {
+		ao.PreserveOwner = true
+	}
+}
+
+// AtomicWriter is an io.WriteCloser that replaces a file atomically. Data is written
+// to a temporary file in the same directory, which on Close is synced to disk and
+// renamed over the target, after which the directory itself is synced. Readers see
+// either the old or the new contents of the file, never a partial write.
+type AtomicWriter struct {
+	path string
+	opt  AtomicWriteOpt
+	temp *os.File
+	done bool
+}
+
+// NewAtomicWriter creates an AtomicWriter that replaces the file at path with perm.
+// Nothing happens to the file at path until Close is called.
+func NewAtomicWriter(path string, perm os.FileMode, opts ...AtomicWriteOption) (*AtomicWriter, error) {
+	w := &AtomicWriter{path: path}
+
+	for _, opt := range opts {
+		opt(&w.opt)
+	}
+
+	temp, err := createTemp(path, perm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %s", err)
+	}
+	w.temp = temp
+
+	return w, nil
+}
+
+// createTemp creates a new temporary file next to path. Unlike os.CreateTemp it is
+// created with perm, so that the umask applies to it just as it does for os.WriteFile.
+func createTemp(path string, perm os.FileMode) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for i := 0; ; i++ {
+		suffix, err := randomSuffix()
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := os.OpenFile(filepath.Join(dir, "."+base+".tmp-"+suffix), os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) && i < 100 {
+			continue
+		}
+		return f, err
+	}
+}
+
+// Write writes p to the temporary file.
+func (w *AtomicWriter) Write(p []byte) (int, error) {
+	return w.temp.Write(p)
+}
+
+// Close syncs the temporary file and renames it over the target. If any step fails
+// the temporary file is removed and the target is left untouched.
+// Calling Close or Abort more than once does nothing.
+func (w *AtomicWriter) Close() error {
+	if w.done {
+		return nil
 	}
+	w.done = true
 
-	if FileExists(dst) { // check if file should be ignored
-		for _, ignore := range ignoreExists {
-			if ignore == path.Base(src) {
-				return nil
+	err := w.commit()
+	if err != nil {
+		w.temp.Close()
+		os.Remove(w.temp.Name())
+	}
+
+	return err
+}
+
+// Abort discards everything written so far and removes the temporary file,
+// leaving the target untouched. Calling Close or Abort more than once does nothing.
+func (w *AtomicWriter) Abort() error {
+	if w.done {
+		return nil
+	}
+	w.done = true
+
+	w.temp.Close()
+	return os.Remove(w.temp.Name())
+}
+
+func (w *AtomicWriter) commit() error {
+	info, statErr := os.Stat(w.path)
+
+	// Otherwise the temporary file already has perm, less the umask.
+	if statErr == nil && w.opt.PreserveMode {
+		if err := w.temp.Chmod(info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to set temp file mode: %s", err)
+		}
+	}
+
+	if statErr == nil && w.opt.PreserveOwner {
+		if uid, gid, ok := fileOwner(info); ok {
+			if err := w.temp.Chown(uid, gid); err != nil {
+				return fmt.Errorf("failed to set temp file owner: %s", err)
 			}
 		}
 	}
 
-	return RenameFile(src, dst)
+	if err := w.temp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temp file: %s", err)
+	}
+
+	if err := w.temp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %s", err)
+	}
+
+	if err := os.Rename(w.temp.Name(), w.path); err != nil {
+		return fmt.Errorf("failed to replace file: %s", err)
+	}
+
+	if err := syncDir(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to sync parent directory: %s", err)
+	}
+
+	return nil
+}
+
+// WriteFileAtomic writes data to the file at path like os.WriteFile, but replaces the
+// file atomically through an AtomicWriter, so that a crash never leaves it truncated.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode, opts ...AtomicWriteOption) error {
+	w, err := NewAtomicWriter(path, perm, opts...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to write temp file: %s", err)
+	}
+
+	return w.Close()
 }
@@ -0,0 +1,19 @@
+//go:build linux || openbsd || dragonfly || solaris
+
+package zdutil
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileAtime returns the access time of the file described by info,
+// falling back to its modification time when it is not available.
+func fileAtime(info os.FileInfo) time.Time {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime()
+	}
+	return time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec))
+}
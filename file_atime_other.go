@@ -0,0 +1,14 @@
+//go:build !linux && !openbsd && !dragonfly && !solaris && !darwin && !freebsd && !netbsd
+
+package zdutil
+
+import (
+	"os"
+	"time"
+)
+
+// fileAtime returns the modification time of the file described by info,
+// since the access time is not portably available on this platform.
+func fileAtime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -0,0 +1,271 @@
+package zdutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0640); err != nil {
+		fmt.Printf("[ERROR] failed to write file:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	if err := WriteFileAtomic(path, []byte("second"), 0600, AtomicPreserveModeOpt()); err != nil {
+		fmt.Printf("[ERROR] failed to replace file:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	data, _ := os.ReadFile(path)
+	info, _ := os.Stat(path)
+	if string(data) != "second" || info.Mode().Perm() != 0640 {
+		fmt.Printf("[ERROR] failed to replace file atomically:\n\t[data=%s]\n\t[mode=%s]\n", data, info.Mode())
+		t.FailNow()
+	}
+
+	w, err := NewAtomicWriter(path, 0600)
+	if err != nil {
+		fmt.Printf("[ERROR] failed to create atomic writer:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+	fmt.Fprint(w, "aborted")
+	w.Abort()
+
+	data, _ = os.ReadFile(path)
+	entries, _ := os.ReadDir(dir)
+	if string(data) != "second" || len(entries) != 1 {
+		fmt.Printf("[ERROR] abort changed the target or left temp files:\n\t[data=%s]\n\t[entries=%d]\n", data, len(entries))
+		t.FailNow()
+	}
+}
+
+func TestWriteFileAtomicUmask(t *testing.T) {
+	dir := t.TempDir()
+	want := filepath.Join(dir, "want.yml")
+	path := filepath.Join(dir, "config.yml")
+
+	for _, perm := range []os.FileMode{0666, 0644, 0600, 0444} {
+		os.Remove(want)
+		os.Remove(path)
+
+		if err := os.WriteFile(want, []byte("data"), perm); err != nil {
+			fmt.Printf("[ERROR] failed to write file:\n\t[error=%v]\n", err)
+			t.FailNow()
+		}
+		if err := WriteFileAtomic(path, []byte("data"), perm); err != nil {
+			fmt.Printf("[ERROR] failed to write file:\n\t[error=%v]\n", err)
+			t.FailNow()
+		}
+
+		wantInfo, _ := os.Stat(want)
+		info, _ := os.Stat(path)
+		if info.Mode() != wantInfo.Mode() {
+			fmt.Printf("[ERROR] mode differs from os.WriteFile:\n\t[perm=%s]\n\t[mode=%s]\n\t[want=%s]\n", perm, info.Mode(), wantInfo.Mode())
+			t.FailNow()
+		}
+	}
+}
+
+func TestRenameDirPreserve(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	dst := filepath.Join(t.TempDir(), "dst")
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// The source stays writable so that its files can be removed without root.
+	os.MkdirAll(filepath.Join(src, "sub"), 0700)
+	os.WriteFile(filepath.Join(src, "sub", "data.txt"), []byte("data"), 0640)
+	os.Chtimes(filepath.Join(src, "sub", "data.txt"), mtime, mtime)
+	os.Chmod(filepath.Join(src, "sub"), 0750)
+	os.Chtimes(filepath.Join(src, "sub"), mtime, mtime)
+
+	if err := RenameDirWithOpts(src, dst); err != nil {
+		fmt.Printf("[ERROR] failed to rename directory:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	for _, tc := range []struct {
+		path string
+		mode os.FileMode
+	}{
+		{path: filepath.Join(dst, "sub"), mode: 0750},
+		{path: filepath.Join(dst, "sub", "data.txt"), mode: 0640},
+	} {
+		info, err := os.Stat(tc.path)
+		if err != nil || info.Mode().Perm() != tc.mode || !info.ModTime().Equal(mtime) {
+			fmt.Printf("[ERROR] failed to preserve metadata:\n\t[path=%s]\n\t[info=%v]\n\t[error=%v]\n", tc.path, info, err)
+			t.FailNow()
+		}
+	}
+
+	os.WriteFile(filepath.Join(src, "plain.txt"), []byte("plain"), 0600)
+	os.Chtimes(filepath.Join(src, "plain.txt"), mtime, mtime)
+	if err := RenameFileWithOpts(filepath.Join(src, "plain.txt"), filepath.Join(dst, "plain.txt"), RenamePreserveOpt(PreserveNone)); err != nil {
+		fmt.Printf("[ERROR] failed to rename file:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	info, _ := os.Stat(filepath.Join(dst, "plain.txt"))
+	if info.ModTime().Equal(mtime) || FileExists(filepath.Join(src, "plain.txt")) {
+		fmt.Printf("[ERROR] unexpected metadata without preserve:\n\t[info=%v]\n", info)
+		t.FailNow()
+	}
+}
+
+func TestRenameConflict(t *testing.T) {
+	older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	// want and wantDir hold the expected files outside of src, keyed by their path
+	// relative to the root, after moving the file and after moving the whole directory.
+	tests := []struct {
+		name    string
+		opt     RenameOption
+		want    map[string]string
+		wantDir map[string]string
+		moved   bool
+		wantErr bool
+	}{
+		{
+			name:    "overwrite",
+			opt:     RenameConflictOpt(ConflictOverwrite),
+			want:    map[string]string{"dst/a.txt": "source"},
+			wantDir: map[string]string{"dst/0.txt": "other", "dst/a.txt": "source"},
+			moved:   true,
+		},
+		{
+			name:    "skip",
+			opt:     RenameConflictOpt(ConflictSkip),
+			want:    map[string]string{"dst/a.txt": "existing"},
+			wantDir: map[string]string{"dst/a.txt": "existing"},
+		},
+		{
+			name:    "error",
+			opt:     RenameConflictOpt(ConflictError),
+			want:    map[string]string{"dst/a.txt": "existing"},
+			wantDir: map[string]string{"dst/a.txt": "existing"},
+			wantErr: true,
+		},
+		{
+			name:    "suffix",
+			opt:     RenameConflictOpt(ConflictRenameWithSuffix),
+			want:    map[string]string{"dst/a.txt": "existing", "dst/a (1).txt": "source"},
+			wantDir: map[string]string{"dst/a.txt": "existing", "dst (1)/0.txt": "other", "dst (1)/a.txt": "source"},
+			moved:   true,
+		},
+		{
+			name:    "newer",
+			opt:     RenameConflictOpt(ConflictKeepNewer),
+			want:    map[string]string{"dst/a.txt": "source"},
+			wantDir: map[string]string{"dst/0.txt": "other", "dst/a.txt": "source"},
+			moved:   true,
+		},
+		{
+			name:    "larger",
+			opt:     RenameConflictOpt(ConflictKeepLarger),
+			want:    map[string]string{"dst/a.txt": "existing"},
+			wantDir: map[string]string{"dst/0.txt": "other", "dst/a.txt": "existing"},
+		},
+		{
+			name: "resolver",
+			opt: RenameResolverOpt(func(src, dst string, srcInfo, dstInfo os.FileInfo) (ConflictPolicy, error) {
+				return ConflictSkip, nil
+			}),
+			want:    map[string]string{"dst/a.txt": "existing"},
+			wantDir: map[string]string{"dst/a.txt": "existing"},
+		},
+	}
+
+	for _, tc := range tests {
+		for _, dir := range []bool{false, true} {
+			root := t.TempDir()
+			src, dst := filepath.Join(root, "src"), filepath.Join(root, "dst")
+			os.Mkdir(src, 0755)
+			os.Mkdir(dst, 0755)
+			os.WriteFile(filepath.Join(src, "a.txt"), []byte("source"), 0644)
+			os.WriteFile(filepath.Join(dst, "a.txt"), []byte("existing"), 0644)
+			os.Chtimes(filepath.Join(src, "a.txt"), newer, newer)
+			os.Chtimes(filepath.Join(dst, "a.txt"), older, older)
+
+			want := tc.want
+			var err error
+			if dir {
+				// 0.txt is walked before the conflict, so it shows whether the
+				// policy was applied before anything was moved.
+				os.WriteFile(filepath.Join(src, "0.txt"), []byte("other"), 0644)
+				want = tc.wantDir
+				err = RenameWithOpts(src, dst, tc.opt)
+			} else {
+				err = RenameWithOpts(filepath.Join(src, "a.txt"), filepath.Join(dst, "a.txt"), tc.opt)
+			}
+
+			if (err != nil) != tc.wantErr || (tc.wantErr && !errors.Is(err, fs.ErrExist)) {
+				fmt.Printf("[ERROR] unexpected error:\n\t[policy=%s]\n\t[dir=%t]\n\t[error=%v]\n", tc.name, dir, err)
+				t.FailNow()
+			}
+
+			got := map[string]string{}
+			filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+				if path == src {
+					return fs.SkipDir
+				}
+				if !entry.IsDir() {
+					rel, _ := filepath.Rel(root, path)
+					data, _ := os.ReadFile(path)
+					got[filepath.ToSlash(rel)] = string(data)
+				}
+				return nil
+			})
+
+			if fmt.Sprint(got) != fmt.Sprint(want) || FileExists(filepath.Join(src, "a.txt")) == tc.moved {
+				fmt.Printf("[ERROR] unexpected files:\n\t[policy=%s]\n\t[dir=%t]\n\t[got=%v]\n\t[want=%v]\n", tc.name, dir, got, want)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestRenameDirFilter(t *testing.T) {
+	for _, tx := range []bool{false, true} {
+		root := t.TempDir()
+		src, dst := filepath.Join(root, "src"), filepath.Join(root, "dst")
+		for _, name := range []string{"a.go", "a_test.go", "docs/readme.md", "lib/util/b.go", "vendor/lib.go", "config.yml"} {
+			os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0755)
+			os.WriteFile(filepath.Join(src, name), []byte(name), 0644)
+		}
+		os.MkdirAll(dst, 0755)
+		os.WriteFile(filepath.Join(dst, "config.yml"), []byte("existing"), 0644)
+
+		include, _ := NewPathMatcher("*.go", "*.yml")
+		exclude, _ := NewPathMatcher("vendor/", "*_test.go")
+		opts := []RenameOption{RenameIncludeOpt(include), RenameExcludeOpt(exclude), RenameIgnoreExistsOpt("*.yml")}
+		if tx {
+			opts = append(opts, RenameTransactionalOpt(""))
+		}
+
+		if err := RenameWithOpts(src, dst, opts...); err != nil {
+			fmt.Printf("[ERROR] failed to rename directory:\n\t[transactional=%t]\n\t[error=%v]\n", tx, err)
+			t.FailNow()
+		}
+
+		for name, moved := range map[string]bool{"a.go": true, "a_test.go": false, "docs/readme.md": false, "lib/util/b.go": true, "vendor/lib.go": false, "config.yml": false} {
+			if FileExists(filepath.Join(src, name)) == moved || FileExists(filepath.Join(dst, name)) != (moved || name == "config.yml") {
+				fmt.Printf("[ERROR] unexpected filter result:\n\t[transactional=%t]\n\t[file=%s]\n\t[moved=%t]\n", tx, name, !moved)
+				t.FailNow()
+			}
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dst, "config.yml"))
+		if string(data) != "existing" || FolderExists(filepath.Join(dst, "vendor")) || FolderExists(filepath.Join(dst, "docs")) {
+			fmt.Printf("[ERROR] unexpected destination:\n\t[transactional=%t]\n\t[config=%s]\n", tx, data)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package zdutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// moveState is the phase a transactional move has reached.
+type moveState string
+
+const (
+	// moveCopying copies every file to a temporary file next to its destination.
+	// A move in this state is reverted by removing what was created.
+	moveCopying moveState = "copying"
+	// moveCommitting renames the temporary files over their destinations, keeping
+	// a backup of every file that is replaced.
+	moveCommitting moveState = "committing"
+	// moveCommitted removes the sources and backups. A move in this state can only
+	// be finished, not reverted.
+	moveCommitted moveState = "committed"
+)
+
+// moveEntry is a file or directory of a transactional move.
+type moveEntry struct {
+	Src     string `json:"src"`
+	Dst     string `json:"dst"`
+	Temp    string `json:"temp,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+	Existed bool   `json:"existed,omitempty"`
+}
+
+// moveJournal records a transactional move so that it can be resumed or reverted.
+// Directories are in walk order, so parents come before their children.
+type moveJournal struct {
+	path     string
+	State    moveState   `json:"state"`
+	Preserve Preserve    `json:"preserve"`
+	Dirs     []moveEntry `json:"dirs"`
+	Files    []moveEntry `json:"files"`
+}
+
+// ResumeRename finishes a transactional move that was interrupted, using the journal
+// written by RenameTransactionalOpt. If the files were still being copied they are
+// copied again. It does nothing if the journal does not exist.
+func ResumeRename(journal string) error {
+	j, err := loadMoveJournal(journal)
+	if j == nil {
+		return err
+	}
+
+	return j.run()
+}
+
+// RevertRename undoes a transactional move that was interrupted, using the journal
+// written by RenameTransactionalOpt, and restores every replaced destination file.
+// It returns an error if the move had already been committed, in which case it can
+// only be finished with ResumeRename. It does nothing if the journal does not exist.
+func RevertRename(journal string) error {
+	j, err := loadMoveJournal(journal)
+	if j == nil {
+		return err
+	}
+
+	if j.State == moveCommitted {
+		return fmt.Errorf("move is already committed and can only be resumed [journal=%s]", journal)
+	}
+
+	return j.revert()
+}
+
+func loadMoveJournal(path string) (*moveJournal, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read move journal: %s", err)
+	}
+
+	j := &moveJournal{path: path}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse move journal [journal=%s]: %s", path, err)
+	}
+
+	return j, nil
+}
+
+// renameDirTx plans the move of src to dst and runs it as a transaction.
+func renameDirTx(src, dst string, opt *RenameOpt) error {
+	if opt.Journal != "" && FileExists(opt.Journal) {
+		return fmt.Errorf("move journal already exists, resume or revert the interrupted move first [journal=%s]", opt.Journal)
+	}
+
+	j, err := planMove(src, dst, opt)
+	if err != nil {
+		return err
+	}
+
+	if err := j.save(); err != nil {
+		return err
+	}
+
+	return j.run()
+}
+
+// planMove walks src with the filters and conflict policy of opt and returns the
+// journal of the move to dst, without changing anything.
+func planMove(src, dst string, opt *RenameOpt) (*moveJournal, error) {
+	type pendingFile struct {
+		rel, src, dst string
+		info          os.FileInfo
+	}
+
+	// The journal may be resumed from another working directory, so every path in it
+	// is absolute.
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return nil, err
+	}
+	dst, err = filepath.Abs(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &moveJournal{State: moveCopying, Preserve: opt.Preserve}
+	if opt.Journal != "" {
+		if j.path, err = filepath.Abs(opt.Journal); err != nil {
+			return nil, err
+		}
+	}
+
+	var pending []pendingFile
+	err = filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		newDst := filepath.Join(dst, path[len(src):])
+		if path != src && !opt.included(path[len(src):], entry.IsDir()) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			info, err := os.Stat(newDst)
+			if err == nil && !info.IsDir() {
+				return fmt.Errorf("destination is not a directory [path=%s]: %w", newDst, fs.ErrExist)
+			}
+			j.Dirs = append(j.Dirs, moveEntry{Src: path, Dst: newDst, Existed: err == nil})
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		pending = append(pending, pendingFile{rel: path[len(src):], src: path, dst: newDst, info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Every file may end up at its own path, and suffixed names are chosen around
+	// those and the names already given out, so no two files share a target.
+	reserved := make(map[string]bool, len(pending))
+	for _, f := range pending {
+		reserved[filepath.Clean(f.dst)] = true
+	}
+
+	for _, f := range pending {
+		target, ok, err := opt.resolve(f.rel, f.src, f.dst, f.info, reserved)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		reserved[filepath.Clean(target)] = true
+
+		suffix, err := randomSuffix()
+		if err != nil {
+			return nil, err
+		}
+
+		dir, base := filepath.Split(target)
+		entry := moveEntry{
+			Src:     f.src,
+			Dst:     target,
+			Temp:    filepath.Join(dir, "."+base+".move-tmp-"+suffix),
+			Backup:  filepath.Join(dir, "."+base+".move-bak-"+suffix),
+			Existed: fileExistsL(target),
+		}
+
+		// The temporary and backup files are trusted to be our own when resuming or
+		// reverting, so they must not clobber anything that is already there.
+		for _, p := range []string{entry.Temp, entry.Backup} {
+			if fileExistsL(p) {
+				return nil, fmt.Errorf("temporary move file already exists [path=%s]: %w", p, fs.ErrExist)
+			}
+		}
+
+		j.Files = append(j.Files, entry)
+	}
+
+	if opt.Include != nil {
+		j.Dirs = usedDirs(j.Dirs, j.Files)
+	}
+
+	return j, nil
+}
+
+// usedDirs returns the directories that already exist or will hold at least one of
+// the files, so that an include filter does not leave empty directories behind.
+func usedDirs(dirs, files []moveEntry) []moveEntry {
+	used := make(map[string]bool, len(dirs))
+	for _, f := range files {
+		for dir := filepath.Dir(filepath.Clean(f.Dst)); !used[dir]; dir = filepath.Dir(dir) {
+			used[dir] = true
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+
+	res := dirs[:0]
+	for _, d := range dirs {
+		if d.Existed || used[filepath.Clean(d.Dst)] {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// run continues the move from its current state. If it fails before the move is
+// committed, everything created so far is removed.
+func (j *moveJournal) run() error {
+	if j.State == moveCopying {
+		if err := j.copy(); err != nil {
+			return j.fail(err)
+		}
+
+		j.State = moveCommitting
+		if err := j.save(); err != nil {
+			return j.fail(err)
+		}
+	}
+
+	if j.State == moveCommitting {
+		if err := j.commit(); err != nil {
+			return j.fail(err)
+		}
+
+		// Committing is idempotent, so a failure to record it is safe to resume.
+		j.State = moveCommitted
+		if err := j.save(); err != nil {
+			return err
+		}
+	}
+
+	return j.cleanup()
+}
+
+// fail reverts the move and returns err, along with any error from the rollback.
+func (j *moveJournal) fail(err error) error {
+	if rerr := j.revert(); rerr != nil {
+		return fmt.Errorf("move failed and could not be rolled back [rollback error=%v]: %w", rerr, err)
+	}
+	return fmt.Errorf("move failed and was rolled back: %w", err)
+}
+
+// copy creates the missing directories and copies every file to its temporary
+// file, verifying the contents.
+func (j *moveJournal) copy() error {
+	for _, d := range j.Dirs {
+		if d.Existed {
+			continue
+		}
+
+		info, err := os.Stat(d.Src)
+		if err != nil {
+			return err
+		}
+
+		err = os.Mkdir(d.Dst, dirPerm(info, j.Preserve))
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+
+	// A resumed copy starts over, and the temporary file left by the previous attempt
+	// may already have the read-only mode of its source.
+	for _, f := range j.Files {
+		if err := removeIfExists(f.Temp); err != nil {
+			return err
+		}
+		if err := copyFile(f.Src, f.Temp, j.Preserve, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commit renames every temporary file over its destination, first moving any
+// existing destination to its backup. Files whose temporary file is gone have
+// already been committed.
+func (j *moveJournal) commit() error {
+	for _, f := range j.Files {
+		if !fileExistsL(f.Temp) {
+			continue
+		}
+
+		if f.Existed && !fileExistsL(f.Backup) {
+			if err := os.Rename(f.Dst, f.Backup); err != nil {
+				return err
+			}
+		}
+
+		if err := os.Rename(f.Temp, f.Dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanup applies the directory metadata, removes the sources, the backups, every
+// source directory that is left empty and finally the journal.
+func (j *moveJournal) cleanup() error {
+	for i := len(j.Dirs) - 1; i >= 0; i-- {
+		d := j.Dirs[i]
+		if d.Existed {
+			continue
+		}
+
+		info, err := os.Stat(d.Src)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := copyMetadata(d.Dst, info, j.Preserve); err != nil {
+			return fmt.Errorf("failed to copy directory metadata: %s", err)
+		}
+	}
+
+	for _, f := range j.Files {
+		if err := removeIfExists(f.Src); err != nil {
+			return fmt.Errorf("failed removing original file: %s", err)
+		}
+		if f.Existed {
+			if err := removeIfExists(f.Backup); err != nil {
+				return fmt.Errorf("failed removing backup file: %s", err)
+			}
+		}
+	}
+
+	// Directories that still hold filtered out files are not empty and are kept.
+	for i := len(j.Dirs) - 1; i >= 0; i-- {
+		os.Remove(j.Dirs[i].Src)
+	}
+
+	return j.remove()
+}
+
+// revert removes the temporary files, restores the backups, removes committed files
+// that did not replace anything and the directories that were created, and finally
+// the journal.
+func (j *moveJournal) revert() error {
+	var first error
+	keep := func(err error) {
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+
+	for i := len(j.Files) - 1; i >= 0; i-- {
+		f := j.Files[i]
+		committed := j.State == moveCommitting && !fileExistsL(f.Temp)
+
+		keep(removeIfExists(f.Temp))
+		switch {
+		case f.Existed && fileExistsL(f.Backup):
+			keep(os.Rename(f.Backup, f.Dst))
+		case !f.Existed && committed:
+			keep(removeIfExists(f.Dst))
+		}
+	}
+
+	for i := len(j.Dirs) - 1; i >= 0; i-- {
+		if !j.Dirs[i].Existed {
+			keep(removeIfExists(j.Dirs[i].Dst))
+		}
+	}
+
+	if first != nil {
+		return first
+	}
+
+	return j.remove()
+}
+
+// save writes the journal atomically, if it has a path.
+func (j *moveJournal) save() error {
+	if j.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := WriteFileAtomic(j.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write move journal: %s", err)
+	}
+	return nil
+}
+
+// remove deletes the journal, if it has a path.
+func (j *moveJournal) remove() error {
+	if j.path == "" {
+		return nil
+	}
+	return removeIfExists(j.path)
+}
+
+// randomSuffix returns a random hex string to make temporary file names unique.
+func randomSuffix() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate temporary file name: %s", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// fileExistsL reports whether anything exists at path, without following symlinks.
+func fileExistsL(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// removeIfExists removes the file or empty directory at path, ignoring that it does not exist.
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,226 @@
+package zdutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newMoveFixture creates a source tree with two files and a destination that
+// already holds a different version of one of them.
+func newMoveFixture(t *testing.T) (src, dst, journal string) {
+	root := t.TempDir()
+	src, dst, journal = filepath.Join(root, "src"), filepath.Join(root, "dst"), filepath.Join(root, "move.journal")
+
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.MkdirAll(dst, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("new a"), 0644)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("new b"), 0644)
+	os.WriteFile(filepath.Join(dst, "a.txt"), []byte("old a"), 0644)
+
+	return src, dst, journal
+}
+
+func checkFile(t *testing.T, path, want string) {
+	data, err := os.ReadFile(path)
+	if (want == "") != (err != nil) || string(data) != want {
+		fmt.Printf("[ERROR] unexpected file contents:\n\t[path=%s]\n\t[data=%s]\n\t[want=%s]\n", path, data, want)
+		t.FailNow()
+	}
+}
+
+func TestRenameDirTransactional(t *testing.T) {
+	src, dst, journal := newMoveFixture(t)
+
+	if err := RenameDirWithOpts(src, dst, RenameTransactionalOpt(journal)); err != nil {
+		fmt.Printf("[ERROR] failed to move directory:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "new a")
+	checkFile(t, filepath.Join(dst, "sub", "b.txt"), "new b")
+	if FolderExists(src) || FileExists(journal) {
+		fmt.Printf("[ERROR] source or journal left behind after move\n")
+		t.FailNow()
+	}
+	if entries, _ := os.ReadDir(dst); len(entries) != 2 {
+		fmt.Printf("[ERROR] temporary files left behind:\n\t[entries=%v]\n", entries)
+		t.FailNow()
+	}
+}
+
+func TestRenameDirTransactionalRollback(t *testing.T) {
+	src, dst, journal := newMoveFixture(t)
+	os.WriteFile(filepath.Join(dst, ".a.txt.move-bak"), []byte("unrelated"), 0644)
+	os.WriteFile(filepath.Join(dst, ".a.txt.move-tmp"), []byte("unrelated"), 0644)
+
+	j, err := planMove(src, dst, newRenameOpt([]RenameOption{RenameTransactionalOpt(journal)}))
+	if err != nil {
+		fmt.Printf("[ERROR] failed to plan move:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+	j.save()
+
+	// Remove the last file after planning so that copying fails halfway.
+	os.Rename(filepath.Join(src, "sub", "b.txt"), filepath.Join(src, "b.txt"))
+	if err := j.run(); err == nil {
+		fmt.Printf("[ERROR] expected move to fail\n")
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(src, "a.txt"), "new a")
+	checkFile(t, filepath.Join(dst, "a.txt"), "old a")
+	checkFile(t, filepath.Join(dst, ".a.txt.move-bak"), "unrelated")
+	checkFile(t, filepath.Join(dst, ".a.txt.move-tmp"), "unrelated")
+	if entries, _ := os.ReadDir(dst); len(entries) != 3 || FileExists(journal) {
+		fmt.Printf("[ERROR] created files or journal left behind after rollback:\n\t[entries=%v]\n", entries)
+		t.FailNow()
+	}
+
+	os.Rename(filepath.Join(src, "b.txt"), filepath.Join(src, "sub", "b.txt"))
+	if err := RenameDirWithOpts(src, dst, RenameTransactionalOpt(journal)); err != nil {
+		fmt.Printf("[ERROR] failed to move directory:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "new a")
+	checkFile(t, filepath.Join(dst, ".a.txt.move-bak"), "unrelated")
+	checkFile(t, filepath.Join(dst, ".a.txt.move-tmp"), "unrelated")
+}
+
+func TestRenameJournal(t *testing.T) {
+	// Interrupted while committing, then reverted.
+	src, dst, journal := newMoveFixture(t)
+
+	j, err := planMove(src, dst, newRenameOpt([]RenameOption{RenameTransactionalOpt(journal)}))
+	if err != nil {
+		fmt.Printf("[ERROR] failed to plan move:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+	j.copy()
+	j.State = moveCommitting
+	j.commit()
+	j.save()
+
+	if err := RenameDirWithOpts(src, dst, RenameTransactionalOpt(journal)); err == nil {
+		fmt.Printf("[ERROR] expected move to refuse an existing journal\n")
+		t.FailNow()
+	}
+
+	if err := RevertRename(journal); err != nil {
+		fmt.Printf("[ERROR] failed to revert move:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "old a")
+	checkFile(t, filepath.Join(src, "sub", "b.txt"), "new b")
+	if FolderExists(filepath.Join(dst, "sub")) || FileExists(journal) {
+		fmt.Printf("[ERROR] created directory or journal left behind after revert\n")
+		t.FailNow()
+	}
+
+	// Interrupted while copying, then resumed.
+	j, _ = planMove(src, dst, newRenameOpt([]RenameOption{RenameTransactionalOpt(journal)}))
+	j.save()
+	os.Mkdir(filepath.Join(dst, "sub"), 0755)
+	os.WriteFile(j.Files[len(j.Files)-1].Temp, []byte("partial"), 0644)
+
+	if err := ResumeRename(journal); err != nil {
+		fmt.Printf("[ERROR] failed to resume move:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "new a")
+	checkFile(t, filepath.Join(dst, "sub", "b.txt"), "new b")
+	if FolderExists(src) || FileExists(journal) {
+		fmt.Printf("[ERROR] source or journal left behind after resume\n")
+		t.FailNow()
+	}
+
+	if err := ResumeRename(journal); err != nil {
+		fmt.Printf("[ERROR] resuming without a journal should do nothing:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+}
+
+func TestResumeRenameReadOnly(t *testing.T) {
+	src, dst, journal := newMoveFixture(t)
+	os.Chmod(filepath.Join(src, "a.txt"), 0444)
+
+	// Interrupted after copying, so the temporary files already have the source mode.
+	opt := newRenameOpt([]RenameOption{RenameTransactionalOpt(journal), RenamePreserveOpt(PreserveMode)})
+	j, err := planMove(src, dst, opt)
+	if err != nil {
+		fmt.Printf("[ERROR] failed to plan move:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+	j.save()
+	j.copy()
+
+	if err := ResumeRename(journal); err != nil {
+		fmt.Printf("[ERROR] failed to resume move of read-only file:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "new a")
+	checkFile(t, filepath.Join(dst, "sub", "b.txt"), "new b")
+	if info, _ := os.Stat(filepath.Join(dst, "a.txt")); info.Mode().Perm() != 0444 {
+		fmt.Printf("[ERROR] mode not preserved after resume:\n\t[mode=%s]\n", info.Mode())
+		t.FailNow()
+	}
+}
+
+func TestResumeRenameRelative(t *testing.T) {
+	src, dst, journal := newMoveFixture(t)
+	root := filepath.Dir(src)
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	// Planned with paths relative to root, then resumed from another directory.
+	os.Chdir(root)
+	opt := newRenameOpt([]RenameOption{RenameTransactionalOpt(filepath.Base(journal))})
+	j, err := planMove(filepath.Base(src), filepath.Base(dst), opt)
+	if err != nil {
+		fmt.Printf("[ERROR] failed to plan move:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+	j.save()
+
+	os.Chdir(t.TempDir())
+	if err := ResumeRename(journal); err != nil {
+		fmt.Printf("[ERROR] failed to resume move from another directory:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "new a")
+	checkFile(t, filepath.Join(dst, "sub", "b.txt"), "new b")
+	if FolderExists(src) || FileExists(journal) {
+		fmt.Printf("[ERROR] source or journal left behind after resume\n")
+		t.FailNow()
+	}
+}
+
+func TestRenameDirTransactionalSuffix(t *testing.T) {
+	src, dst, journal := newMoveFixture(t)
+	os.WriteFile(filepath.Join(src, "a (1).txt"), []byte("new a (1)"), 0644)
+
+	// Merge the directories, but keep both versions of conflicting files.
+	resolver := func(src, dst string, srcInfo, dstInfo os.FileInfo) (ConflictPolicy, error) {
+		if srcInfo.IsDir() {
+			return ConflictOverwrite, nil
+		}
+		return ConflictRenameWithSuffix, nil
+	}
+
+	if err := RenameDirWithOpts(src, dst, RenameTransactionalOpt(journal), RenameResolverOpt(resolver)); err != nil {
+		fmt.Printf("[ERROR] failed to move directory:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	checkFile(t, filepath.Join(dst, "a.txt"), "old a")
+	checkFile(t, filepath.Join(dst, "a (1).txt"), "new a (1)")
+	checkFile(t, filepath.Join(dst, "a (2).txt"), "new a")
+	checkFile(t, filepath.Join(dst, "sub", "b.txt"), "new b")
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package zdutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the uid and gid of the file described by info.
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
+
+// syncDir flushes the directory entry changes of dir to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
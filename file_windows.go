@@ -0,0 +1,15 @@
+//go:build windows
+
+package zdutil
+
+import "os"
+
+// fileOwner is not supported on windows and always reports false.
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// syncDir is a no-op on windows, where directories cannot be opened for syncing.
+func syncDir(dir string) error {
+	return nil
+}
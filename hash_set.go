@@ -0,0 +1,295 @@
+package zdutil
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hasher defines how a HashSet hashes and compares its elements. Elements that
+// are Equal must have the same Hash.
+type Hasher[T any] interface {
+	Hash(T) uint64
+	Equal(a, b T) bool
+}
+
+// BytesHasher hashes and compares byte slices by their contents.
+type BytesHasher struct{}
+
+// Hash returns the FNV-1a hash of b.
+func (BytesHasher) Hash(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// Equal reports whether a and b hold the same bytes.
+func (BytesHasher) Equal(a, b []byte) bool {
+	return bytes.Equal(a, b)
+}
+
+// FoldedStringHasher hashes and compares strings case-insensitively,
+// using the same Unicode case folding as strings.EqualFold.
+type FoldedStringHasher struct{}
+
+// Hash returns the FNV-1a hash of s with every rune replaced by the smallest
+// rune it folds to, so that strings that are equal under folding hash the same.
+func (FoldedStringHasher) Hash(s string) uint64 {
+	h := fnv.New64a()
+
+	var buf [utf8.UTFMax]byte
+	for _, r := range s {
+		folded := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < folded {
+				folded = f
+			}
+		}
+
+		n := utf8.EncodeRune(buf[:], folded)
+		h.Write(buf[:n])
+	}
+
+	return h.Sum64()
+}
+
+// Equal reports whether a and b are equal under Unicode case folding.
+func (FoldedStringHasher) Equal(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// KeyHasher hashes and compares elements by a comparable key taken from each
+// element, such as one or more fields of a struct.
+type KeyHasher[T any, K comparable] struct {
+	Key func(T) K
+}
+
+// NewKeyHasher creates a KeyHasher that identifies elements by key.
+func NewKeyHasher[T any, K comparable](key func(T) K) KeyHasher[T, K] {
+	return KeyHasher[T, K]{Key: key}
+}
+
+// Hash returns the FNV-1a hash of the key of v. Keys are hashed the way == compares
+// them: pointers and channels by address rather than by what they point to, and
+// positive and negative zero the same.
+func (k KeyHasher[T, K]) Hash(v T) uint64 {
+	key := k.Key(v)
+
+	// Common key types are hashed without reflection, which would allocate.
+	switch key := any(key).(type) {
+	case string:
+		return fnvString(fnvOffset64, key)
+	case int:
+		return fnvUint64(fnvOffset64, uint64(key))
+	case int64:
+		return fnvUint64(fnvOffset64, uint64(key))
+	case int32:
+		return fnvUint64(fnvOffset64, uint64(key))
+	case uint:
+		return fnvUint64(fnvOffset64, uint64(key))
+	case uint64:
+		return fnvUint64(fnvOffset64, key)
+	case uint32:
+		return fnvUint64(fnvOffset64, uint64(key))
+	case float64:
+		return fnvUint64(fnvOffset64, floatBits(key))
+	}
+
+	return hashValue(fnvOffset64, reflect.ValueOf(any(key)))
+}
+
+// Equal reports whether a and b have the same key.
+func (k KeyHasher[T, K]) Equal(a, b T) bool {
+	return k.Key(a) == k.Key(b)
+}
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// fnvUint64 adds the 8 bytes of x to the FNV-1a hash h.
+func fnvUint64(h, x uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= x & 0xff
+		h *= fnvPrime64
+		x >>= 8
+	}
+	return h
+}
+
+// fnvString adds the bytes of s to the FNV-1a hash h.
+func fnvString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// floatBits returns the bits of f, with negative zero folded into positive zero
+// since they compare equal.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+// hashValue adds the comparable value v to the FNV-1a hash h, so that values that
+// are == hash the same.
+func hashValue(h uint64, v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.String:
+		return fnvString(h, v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			return fnvUint64(h, 1)
+		}
+		return fnvUint64(h, 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fnvUint64(h, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fnvUint64(h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fnvUint64(h, floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return fnvUint64(fnvUint64(h, floatBits(real(c))), floatBits(imag(c)))
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return fnvUint64(h, uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return h
+		}
+		return hashValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			h = hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		// Blank fields are ignored by ==.
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).Name != "_" {
+				h = hashValue(h, v.Field(i))
+			}
+		}
+	}
+	return h
+}
+
+// HashSet is a set of elements that do not need to be comparable, such as slices,
+// maps or structs containing them. Elements are identified through a Hasher and
+// elements with the same hash are kept in a collision chain.
+type HashSet[T any] struct {
+	hasher  Hasher[T]
+	buckets map[uint64][]T
+	size    int
+}
+
+// NewHashSet creates a new hash set using hasher, containing the specified elements.
+// Duplicates in the input are ignored, keeping the first occurrence.
+func NewHashSet[T any](hasher Hasher[T], elems ...T) *HashSet[T] {
+	s := &HashSet[T]{
+		hasher:  hasher,
+		buckets: make(map[uint64][]T, len(elems)),
+	}
+	s.Add(elems...)
+
+	return s
+}
+
+// find returns the hash of element and its position in the collision chain, or -1.
+func (s *HashSet[T]) find(element T) (uint64, int) {
+	h := s.hasher.Hash(element)
+	for i, elem := range s.buckets[h] {
+		if s.hasher.Equal(elem, element) {
+			return h, i
+		}
+	}
+	return h, -1
+}
+
+// Add adds the specified elements to the set.
+//
+// The function is a no-op for elements that are already equal to one in the set.
+func (s *HashSet[T]) Add(elems ...T) {
+	for i := range elems {
+		h, pos := s.find(elems[i])
+		if pos >= 0 {
+			continue
+		}
+
+		s.buckets[h] = append(s.buckets[h], elems[i])
+		s.size++
+	}
+}
+
+// Remove deletes the element equal to the specified one from the set.
+// If no such element exists in the set, the function does nothing.
+func (s *HashSet[T]) Remove(element T) {
+	h, pos := s.find(element)
+	if pos < 0 {
+		return
+	}
+
+	chain := s.buckets[h]
+	if len(chain) == 1 {
+		delete(s.buckets, h)
+	} else {
+		s.buckets[h] = append(chain[:pos:pos], chain[pos+1:]...)
+	}
+	s.size--
+}
+
+// Contains checks if the set contains all of the given elements, following the
+// same rules as Set.Contains.
+func (s *HashSet[T]) Contains(elems ...T) bool {
+	if len(elems) == 0 && s.Len() > 0 {
+		return false
+	}
+
+	for i := range elems {
+		if _, pos := s.find(elems[i]); pos < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Len returns the number of elements in the set.
+func (s *HashSet[T]) Len() int {
+	return s.size
+}
+
+// Clear resets the set to its initial state, removing all elements.
+func (s *HashSet[T]) Clear() {
+	s.buckets = make(map[uint64][]T)
+	s.size = 0
+}
+
+// Values returns all elements in the set.
+func (s *HashSet[T]) Values() []T {
+	values := make([]T, 0, s.size)
+	for _, chain := range s.buckets {
+		values = append(values, chain...)
+	}
+	return values
+}
+
+// Each calls fn for every element in the set, in unspecified order,
+// until fn returns false.
+func (s *HashSet[T]) Each(fn func(T) bool) {
+	for _, chain := range s.buckets {
+		for i := range chain {
+			if !fn(chain[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package zdutil
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+type collidingHasher struct{}
+
+func (collidingHasher) Hash([]int) uint64 { return 1 }
+
+func (collidingHasher) Equal(a, b []int) bool { return fmt.Sprint(a) == fmt.Sprint(b) }
+
+func TestHashSet(t *testing.T) {
+	bytesSet := NewHashSet[[]byte](BytesHasher{}, []byte("a"), []byte("b"), []byte("a"))
+	if bytesSet.Len() != 2 || !bytesSet.Contains([]byte("b")) || bytesSet.Contains([]byte("c")) {
+		fmt.Printf("[ERROR] failed to store byte slices:\n\t[got=%q]\n", bytesSet.Values())
+		t.FailNow()
+	}
+
+	folded := NewHashSet[string](FoldedStringHasher{}, "Hello", "HELLO", "straße", "Kelvin")
+	if folded.Len() != 3 || !folded.Contains("hello", "STRAßE", "Kelvin") {
+		fmt.Printf("[ERROR] failed to fold strings:\n\t[got=%q]\n", folded.Values())
+		t.FailNow()
+	}
+
+	type user struct {
+		ID   int
+		Tags []string
+	}
+	users := NewHashSet[user](NewKeyHasher(func(u user) int { return u.ID }),
+		user{ID: 1, Tags: []string{"a"}},
+		user{ID: 2},
+		user{ID: 1, Tags: []string{"b"}},
+	)
+	if users.Len() != 2 || !users.Contains(user{ID: 2}) {
+		fmt.Printf("[ERROR] failed to key structs:\n\t[got=%v]\n", users.Values())
+		t.FailNow()
+	}
+
+	chained := NewHashSet[[]int](collidingHasher{}, []int{1}, []int{2}, []int{3})
+	chained.Remove([]int{2})
+	if chained.Len() != 2 || !chained.Contains([]int{1}, []int{3}) || chained.Contains([]int{2}) {
+		fmt.Printf("[ERROR] failed to handle collision chain:\n\t[got=%v]\n", chained.Values())
+		t.FailNow()
+	}
+}
+
+func TestKeyHasher(t *testing.T) {
+	type node struct{ N int }
+	n := &node{N: 1}
+	pointers := NewHashSet[*node](NewKeyHasher(func(n *node) *node { return n }), n)
+	n.N = 2
+	if !pointers.Contains(n) || pointers.Contains(&node{N: 2}) {
+		fmt.Printf("[ERROR] pointer keys not hashed by address:\n\t[got=%v]\n", pointers.Values())
+		t.FailNow()
+	}
+
+	floats := NewHashSet[float64](NewKeyHasher(func(f float64) float64 { return f }), 0.0)
+	if !floats.Contains(math.Copysign(0, -1)) {
+		fmt.Printf("[ERROR] negative zero not equal to zero:\n\t[got=%v]\n", floats.Values())
+		t.FailNow()
+	}
+
+	type key struct {
+		Name  string
+		Score float64
+		Ref   *node
+	}
+	structs := NewHashSet[key](NewKeyHasher(func(k key) key { return k }), key{"a", 0, n}, key{"b", 1, nil})
+	if structs.Len() != 2 || !structs.Contains(key{"a", math.Copysign(0, -1), n}) || structs.Contains(key{"a", 0, nil}) {
+		fmt.Printf("[ERROR] failed to hash struct keys:\n\t[got=%v]\n", structs.Values())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,291 @@
+package zdutil
+
+import "sync"
+
+// HistoryEvent describes the kind of change reported to History listeners.
+type HistoryEvent int
+
+const (
+	HistoryDo HistoryEvent = iota
+	HistoryUndo
+	HistoryRedo
+	HistoryClear
+)
+
+// String returns the name of the event.
+func (e HistoryEvent) String() string {
+	switch e {
+	case HistoryDo:
+		return "do"
+	case HistoryUndo:
+		return "undo"
+	case HistoryRedo:
+		return "redo"
+	case HistoryClear:
+		return "clear"
+	}
+	return "unknown"
+}
+
+type historyEntry[T any] struct {
+	id     uint64
+	values []T
+}
+
+type History[T any] struct {
+	undo Stack[historyEntry[T]]
+	redo Stack[historyEntry[T]]
+
+	group      []T
+	groupDepth int
+
+	maxDepth  int
+	lastID    uint64
+	floor     uint64
+	savepoint uint64
+
+	listeners []func(HistoryEvent, []T)
+	m         sync.Mutex
+}
+
+// NewHistory creates an empty History that keeps at most maxDepth undo entries.
+// A maxDepth of zero or less keeps every entry.
+func NewHistory[T any](maxDepth int) *History[T] {
+	return &History[T]{maxDepth: maxDepth}
+}
+
+// OnChange registers a listener that is called after every Do, Undo, Redo and Clear
+// with the kind of change and the values involved. Listeners are called without
+// holding the history lock, so they may safely call back into the history.
+func (h *History[T]) OnChange(fn func(HistoryEvent, []T)) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.listeners = append(h.listeners, fn)
+}
+
+// Do records the given values as a single undo entry and clears the redo history.
+// If a transaction is open the values are added to it instead, and are recorded
+// once the outermost transaction is committed.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Do(values ...T) {
+	if len(values) == 0 {
+		return
+	}
+
+	h.m.Lock()
+	if h.groupDepth > 0 {
+		h.group = append(h.group, values...)
+		h.m.Unlock()
+		return
+	}
+
+	values = append([]T(nil), values...)
+	h.push(values)
+	listeners := h.listeners
+	h.m.Unlock()
+
+	notifyHistory(listeners, HistoryDo, values)
+}
+
+// Begin opens a transaction. Values passed to Do until the matching Commit are
+// grouped into a single undo entry. Transactions may be nested, in which case
+// only the outermost Commit records the entry.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Begin() {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.groupDepth++
+}
+
+// Commit closes the current transaction. When the outermost transaction is
+// committed, the grouped values are recorded as a single undo entry.
+// The function does nothing if no transaction is open.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Commit() {
+	h.m.Lock()
+	if h.groupDepth == 0 {
+		h.m.Unlock()
+		return
+	}
+
+	h.groupDepth--
+	if h.groupDepth > 0 || len(h.group) == 0 {
+		h.m.Unlock()
+		return
+	}
+
+	values := h.group
+	h.group = nil
+	h.push(values)
+	listeners := h.listeners
+	h.m.Unlock()
+
+	notifyHistory(listeners, HistoryDo, values)
+}
+
+// Rollback discards every open transaction without recording it and returns the
+// discarded values in the order they should be reverted, most recent first.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Rollback() []T {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	values := reversed(h.group)
+	h.group = nil
+	h.groupDepth = 0
+
+	return values
+}
+
+// Undo moves the most recent entry to the redo history and returns its values in
+// the order they should be reverted, most recent first.
+// The function returns nil if there is nothing to undo or a transaction is open.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Undo() []T {
+	h.m.Lock()
+	if h.groupDepth > 0 {
+		h.m.Unlock()
+		return nil
+	}
+
+	entry := h.undo.Pop()
+	if entry == nil {
+		h.m.Unlock()
+		return nil
+	}
+
+	h.redo.Push(*entry)
+	listeners := h.listeners
+	h.m.Unlock()
+
+	values := reversed(entry.values)
+	notifyHistory(listeners, HistoryUndo, values)
+
+	return values
+}
+
+// Redo moves the most recently undone entry back to the undo history and returns
+// its values in the order they should be reapplied.
+// The function returns nil if there is nothing to redo or a transaction is open.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Redo() []T {
+	h.m.Lock()
+	if h.groupDepth > 0 {
+		h.m.Unlock()
+		return nil
+	}
+
+	entry := h.redo.Pop()
+	if entry == nil {
+		h.m.Unlock()
+		return nil
+	}
+
+	h.undo.Push(*entry)
+	listeners := h.listeners
+	h.m.Unlock()
+
+	values := append([]T(nil), entry.values...)
+	notifyHistory(listeners, HistoryRedo, values)
+
+	return values
+}
+
+// CanUndo reports whether there is an entry to undo.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) CanUndo() bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.groupDepth == 0 && h.undo.Len() > 0
+}
+
+// CanRedo reports whether there is an entry to redo.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) CanRedo() bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.groupDepth == 0 && h.redo.Len() > 0
+}
+
+// MarkSaved records the current position in the history as the savepoint.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) MarkSaved() {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.savepoint = h.current()
+}
+
+// IsDirty reports whether the history has moved away from the last savepoint,
+// either through new entries, undo or redo.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) IsDirty() bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return len(h.group) > 0 || h.current() != h.savepoint
+}
+
+// Clear removes every undo and redo entry, discards open transactions and
+// resets the savepoint to the now empty history.
+// It acquires a lock to ensure thread-safe access.
+func (h *History[T]) Clear() {
+	h.m.Lock()
+	h.undo.Clear()
+	h.redo.Clear()
+	h.group = nil
+	h.groupDepth = 0
+	h.floor = 0
+	h.savepoint = 0
+	listeners := h.listeners
+	h.m.Unlock()
+
+	notifyHistory(listeners, HistoryClear, nil)
+}
+
+// push records values as a new undo entry, clears the redo history and
+// trims the undo history to the max depth. The caller must hold the lock.
+func (h *History[T]) push(values []T) {
+	h.lastID++
+	h.undo.Push(historyEntry[T]{id: h.lastID, values: values})
+	h.redo.Clear()
+
+	if h.maxDepth > 0 {
+		if dropped := h.undo.trim(h.maxDepth); len(dropped) > 0 {
+			h.floor = dropped[0].id
+		}
+	}
+}
+
+// current returns the id of the entry on top of the undo history. When it is empty
+// that is the newest entry trimmed away, whose changes are still applied, or zero
+// if nothing was ever trimmed. A savepoint on an older trimmed entry can therefore
+// never be reached again. The caller must hold the lock.
+func (h *History[T]) current() uint64 {
+	if h.undo.Len() == 0 {
+		return h.floor
+	}
+	return h.undo.Peek().id
+}
+
+func notifyHistory[T any](listeners []func(HistoryEvent, []T), event HistoryEvent, values []T) {
+	for _, fn := range listeners {
+		fn(event, values)
+	}
+}
+
+func reversed[T any](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+
+	res := make([]T, len(values))
+	for i := range values {
+		res[len(values)-1-i] = values[i]
+	}
+	return res
+}
@@ -0,0 +1,139 @@
+package zdutil
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	history := NewHistory[int](2)
+
+	var events []HistoryEvent
+	history.OnChange(func(e HistoryEvent, _ []int) {
+		events = append(events, e)
+	})
+
+	history.Do(1)
+	history.MarkSaved()
+	history.Begin()
+	history.Do(2)
+	history.Do(3)
+	history.Commit()
+
+	if !history.IsDirty() {
+		fmt.Println("[ERROR] expected history to be dirty after new entries")
+		t.FailNow()
+	}
+
+	want := []int{3, 2}
+	got := history.Undo()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to undo transaction:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	if history.IsDirty() {
+		fmt.Println("[ERROR] expected history to be clean after undoing to savepoint")
+		t.FailNow()
+	}
+
+	want = []int{2, 3}
+	got = history.Redo()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to redo transaction:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	history.Do(4)
+	if history.CanRedo() {
+		fmt.Println("[ERROR] expected redo history to be cleared after new entry")
+		t.FailNow()
+	}
+
+	// max depth of 2 drops the oldest entry
+	history.Undo()
+	history.Undo()
+	if history.CanUndo() {
+		fmt.Println("[ERROR] expected undo history to be trimmed to max depth")
+		t.FailNow()
+	}
+
+	wantEvents := []HistoryEvent{HistoryDo, HistoryDo, HistoryUndo, HistoryRedo, HistoryDo, HistoryUndo, HistoryUndo}
+	if fmt.Sprint(events) != fmt.Sprint(wantEvents) {
+		fmt.Printf("[ERROR] failed to notify listeners:\n\t[got=%v]\n\t[want=%v]\n", events, wantEvents)
+		t.FailNow()
+	}
+}
+
+func TestHistorySavepointTrimmed(t *testing.T) {
+	history := NewHistory[int](1)
+	history.Do(1)
+	history.Do(2)
+	history.Undo()
+
+	if !history.IsDirty() {
+		fmt.Println("[ERROR] expected history with trimmed changes to be dirty")
+		t.FailNow()
+	}
+
+	history.Redo()
+	history.MarkSaved()
+	history.Do(3)
+	history.Undo()
+
+	if history.IsDirty() {
+		fmt.Println("[ERROR] expected trimmed savepoint to be reached by undo")
+		t.FailNow()
+	}
+
+	history.Redo()
+	if !history.IsDirty() {
+		fmt.Println("[ERROR] expected redo past the savepoint to be dirty")
+		t.FailNow()
+	}
+}
+
+func TestHistoryConcurrent(t *testing.T) {
+	history := NewHistory[int](0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				history.Do(i*100 + j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Undo and Redo only move entries between the two stacks, so however the
+	// goroutines interleave every entry is still there afterwards.
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				history.Undo()
+				history.CanRedo()
+				history.Redo()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for history.Redo() != nil {
+	}
+
+	count := 0
+	for history.Undo() != nil {
+		count++
+	}
+
+	if count != 800 {
+		fmt.Printf("[ERROR] failed to record concurrent entries:\n\t[got=%d]\n\t[want=%d]\n", count, 800)
+		t.FailNow()
+	}
+}
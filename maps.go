@@ -0,0 +1,125 @@
+package zdutil
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Keys returns the keys of m in unspecified order.
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns the values of m in unspecified order.
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// SortedKeys returns the keys of m sorted in their natural order.
+func SortedKeys[K Ordered, V any](m map[K]V) []K {
+	keys := Keys(m)
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// SortedMapValues returns the values of m sorted in their natural order.
+// It is named apart from SortedValues, which sorts the elements of a Set.
+func SortedMapValues[K comparable, V Ordered](m map[K]V) []V {
+	values := Values(m)
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
+
+// Merge returns a new map holding every entry of the given maps. When a key exists
+// in more than one map, resolve is called with the key, the value merged so far and
+// the new value, and its result is kept. A nil resolve keeps the last value.
+func Merge[K comparable, V any](resolve func(key K, current, next V) V, maps ...map[K]V) map[K]V {
+	size := 0
+	for _, m := range maps {
+		size += len(m)
+	}
+
+	res := make(map[K]V, size)
+	for _, m := range maps {
+		for k, v := range m {
+			if current, ok := res[k]; ok && resolve != nil {
+				v = resolve(k, current, v)
+			}
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
+// Invert returns a new map from each value of m to its key. When several keys
+// share a value, which of them is kept is unspecified.
+func Invert[K, V comparable](m map[K]V) map[V]K {
+	res := make(map[V]K, len(m))
+	for k, v := range m {
+		res[v] = k
+	}
+	return res
+}
+
+// FilterMap returns a new map holding the entries of m for which pred returns true.
+func FilterMap[K comparable, V any](m map[K]V, pred func(K, V) bool) map[K]V {
+	res := make(map[K]V)
+	for k, v := range m {
+		if pred(k, v) {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// MapValues returns a new map with the same keys as m and fn applied to every value.
+func MapValues[K comparable, V, U any](m map[K]V, fn func(V) U) map[K]U {
+	res := make(map[K]U, len(m))
+	for k, v := range m {
+		res[k] = fn(v)
+	}
+	return res
+}
+
+// DeepEqual reports whether a and b have the same keys and equal values for every key.
+// Values are compared with equal, or with reflect.DeepEqual if equal is nil.
+func DeepEqual[K comparable, V any](a, b map[K]V, equal func(V, V) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, va := range a {
+		vb, ok := b[k]
+		if !ok {
+			return false
+		}
+
+		if equal == nil {
+			if !reflect.DeepEqual(va, vb) {
+				return false
+			}
+		} else if !equal(va, vb) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetFromMap returns a Set of the keys of m, such as a map[K]struct{} used as a set.
+func SetFromMap[K comparable, V any](m map[K]V) *Set[K] {
+	set := &Set[K]{elements: make(map[K]struct{}, len(m))}
+	for k := range m {
+		set.elements[k] = struct{}{}
+	}
+	return set
+}
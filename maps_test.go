@@ -0,0 +1,45 @@
+package zdutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaps(t *testing.T) {
+	a := map[string]int{"b": 2, "a": 1, "c": 3}
+	b := map[string]int{"c": 30, "d": 4}
+
+	sum := func(_ string, current, next int) int { return current + next }
+	isOdd := func(_ string, v int) bool { return v%2 == 1 }
+
+	cases := map[string]struct {
+		got  interface{}
+		want string
+	}{
+		"sorted keys":   {SortedKeys(a), "[a b c]"},
+		"sorted values": {SortedMapValues(a), "[1 2 3]"},
+		"merge":         {Merge(sum, a, b), "map[a:1 b:2 c:33 d:4]"},
+		"merge last":    {Merge(nil, a, b), "map[a:1 b:2 c:30 d:4]"},
+		"invert":        {Invert(a), "map[1:a 2:b 3:c]"},
+		"filter":        {FilterMap(a, isOdd), "map[a:1 c:3]"},
+		"map values":    {MapValues(a, func(v int) string { return strings.Repeat("x", v) }), "map[a:x b:xx c:xxx]"},
+		"set from map":  {SortedValues(SetFromMap(map[int]struct{}{2: {}, 1: {}})), "[1 2]"},
+		"set to map":    {NewSet(1, 2).ToMap(), "map[1:{} 2:{}]"},
+	}
+
+	for name, c := range cases {
+		if got := fmt.Sprint(c.got); got != c.want {
+			fmt.Printf("[ERROR] failed %s:\n\t[got=%s]\n\t[want=%s]\n", name, got, c.want)
+			t.Fail()
+		}
+	}
+
+	x := map[string][]int{"a": {1, 2}}
+	y := map[string][]int{"a": {1, 2}}
+	sameLen := func(p, q []int) bool { return len(p) == len(q) }
+	if !DeepEqual(x, y, nil) || !DeepEqual(x, map[string][]int{"a": {3, 4}}, sameLen) || DeepEqual(x, map[string][]int{"b": {1, 2}}, nil) {
+		fmt.Println("[ERROR] failed to compare maps")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,280 @@
+package zdutil
+
+import (
+	"container/list"
+	"encoding/json"
+)
+
+// OrderedSet is a set that remembers the order in which elements were added.
+// Add, Remove, Contains, MoveToFront and MoveToBack run in constant time,
+// while At and IndexOf walk the set and run in linear time.
+type OrderedSet[T comparable] struct {
+	order    *list.List
+	elements map[T]*list.Element
+}
+
+// NewOrderedSet creates a new ordered set containing the specified elements in order.
+// Duplicates in the input are ignored, keeping the position of the first occurrence.
+func NewOrderedSet[T comparable](elems ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{
+		order:    list.New(),
+		elements: make(map[T]*list.Element, len(elems)),
+	}
+	s.Add(elems...)
+
+	return s
+}
+
+// Add appends the specified elements to the back of the set.
+// Elements that already exist in the set keep their current position.
+func (s *OrderedSet[T]) Add(elems ...T) {
+	if s.elements == nil {
+		s.order = list.New()
+		s.elements = make(map[T]*list.Element, len(elems))
+	}
+
+	for i := range elems {
+		if _, ok := s.elements[elems[i]]; ok {
+			continue
+		}
+		s.elements[elems[i]] = s.order.PushBack(elems[i])
+	}
+}
+
+// Remove deletes the specified element from the set.
+// If the element does not exist in the set, the function does nothing.
+func (s *OrderedSet[T]) Remove(element T) {
+	if e, ok := s.elements[element]; ok {
+		s.order.Remove(e)
+		delete(s.elements, element)
+	}
+}
+
+// Contains checks if the set contains all of the given elements, following the
+// same rules as Set.Contains.
+func (s *OrderedSet[T]) Contains(elems ...T) bool {
+	if len(elems) == 0 && s.Len() > 0 {
+		return false
+	}
+
+	for i := range elems {
+		if _, ok := s.elements[elems[i]]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Len returns the number of elements in the set.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.elements)
+}
+
+// Clear resets the set to its initial state, removing all elements.
+func (s *OrderedSet[T]) Clear() {
+	s.order = list.New()
+	s.elements = make(map[T]*list.Element)
+}
+
+// Values returns all elements in the set, in order.
+func (s *OrderedSet[T]) Values() []T {
+	values := make([]T, 0, s.Len())
+	s.Each(func(elem T) bool {
+		values = append(values, elem)
+		return true
+	})
+	return values
+}
+
+// Each calls fn for every element in the set, in order, until fn returns false.
+func (s *OrderedSet[T]) Each(fn func(T) bool) {
+	if s.order == nil {
+		return
+	}
+
+	for e := s.order.Front(); e != nil; e = e.Next() {
+		if !fn(e.Value.(T)) {
+			return
+		}
+	}
+}
+
+// At returns the element at position i and true, or the zero value and false
+// if i is out of range. It walks from whichever end of the set is closer.
+func (s *OrderedSet[T]) At(i int) (T, bool) {
+	var zero T
+	if i < 0 || i >= s.Len() {
+		return zero, false
+	}
+
+	if i < s.Len()/2 {
+		e := s.order.Front()
+		for ; i > 0; i-- {
+			e = e.Next()
+		}
+		return e.Value.(T), true
+	}
+
+	e := s.order.Back()
+	for i = s.Len() - 1 - i; i > 0; i-- {
+		e = e.Prev()
+	}
+	return e.Value.(T), true
+}
+
+// IndexOf returns the position of the element in the set, or -1 if it does not exist.
+func (s *OrderedSet[T]) IndexOf(element T) int {
+	target, ok := s.elements[element]
+	if !ok {
+		return -1
+	}
+
+	i := 0
+	for e := s.order.Front(); e != target; e = e.Next() {
+		i++
+	}
+	return i
+}
+
+// MoveToFront moves the element to the front of the set.
+// If the element does not exist in the set, the function does nothing.
+func (s *OrderedSet[T]) MoveToFront(element T) {
+	if e, ok := s.elements[element]; ok {
+		s.order.MoveToFront(e)
+	}
+}
+
+// MoveToBack moves the element to the back of the set.
+// If the element does not exist in the set, the function does nothing.
+func (s *OrderedSet[T]) MoveToBack(element T) {
+	if e, ok := s.elements[element]; ok {
+		s.order.MoveToBack(e)
+	}
+}
+
+// ToSet returns an unordered Set containing the elements of s.
+func (s *OrderedSet[T]) ToSet() *Set[T] {
+	set := &Set[T]{elements: make(map[T]struct{}, s.Len())}
+	for elem := range s.elements {
+		set.elements[elem] = struct{}{}
+	}
+	return set
+}
+
+// filter returns a new ordered set with the elements of s, in order, for which keep returns true.
+func (s *OrderedSet[T]) filter(keep func(T) bool) *OrderedSet[T] {
+	res := NewOrderedSet[T]()
+	s.Each(func(elem T) bool {
+		if keep(elem) {
+			res.Add(elem)
+		}
+		return true
+	})
+	return res
+}
+
+// Intersect returns a new ordered set containing the elements that exist in both
+// s and a, in the order of s.
+func (s *OrderedSet[T]) Intersect(a *OrderedSet[T]) *OrderedSet[T] {
+	return s.filter(func(elem T) bool {
+		_, ok := a.elements[elem]
+		return ok
+	})
+}
+
+// Union returns a new ordered set containing the elements of s in order,
+// followed by the elements of a that do not exist in s.
+func (s *OrderedSet[T]) Union(a *OrderedSet[T]) *OrderedSet[T] {
+	union := NewOrderedSet(s.Values()...)
+	union.Add(a.Values()...)
+	return union
+}
+
+// ExceptRight returns a new ordered set containing the elements of s that do
+// not exist in a, in the order of s.
+func (s *OrderedSet[T]) ExceptRight(a *OrderedSet[T]) *OrderedSet[T] {
+	return s.filter(func(elem T) bool {
+		_, ok := a.elements[elem]
+		return !ok
+	})
+}
+
+// ExceptLeft returns a new ordered set containing the elements of a that do
+// not exist in s, in the order of a.
+func (s *OrderedSet[T]) ExceptLeft(a *OrderedSet[T]) *OrderedSet[T] {
+	return a.ExceptRight(s)
+}
+
+// SymmetricDifference returns a new ordered set containing the elements that exist
+// in exactly one of s and a, in the order of s followed by the order of a.
+func (s *OrderedSet[T]) SymmetricDifference(a *OrderedSet[T]) *OrderedSet[T] {
+	diff := s.ExceptRight(a)
+	diff.Add(s.ExceptLeft(a).Values()...)
+	return diff
+}
+
+// IsSubset reports whether every element of s exists in a.
+func (s *OrderedSet[T]) IsSubset(a *OrderedSet[T]) bool {
+	if s.Len() > a.Len() {
+		return false
+	}
+
+	for elem := range s.elements {
+		if _, ok := a.elements[elem]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSuperset reports whether every element of a exists in s.
+func (s *OrderedSet[T]) IsSuperset(a *OrderedSet[T]) bool {
+	return a.IsSubset(s)
+}
+
+// IsProperSubset reports whether s is a subset of a and a has at least one
+// element that does not exist in s.
+func (s *OrderedSet[T]) IsProperSubset(a *OrderedSet[T]) bool {
+	return s.Len() < a.Len() && s.IsSubset(a)
+}
+
+// Equal reports whether s and a contain exactly the same elements, ignoring order.
+func (s *OrderedSet[T]) Equal(a *OrderedSet[T]) bool {
+	return s.Len() == a.Len() && s.IsSubset(a)
+}
+
+// IsDisjoint reports whether s and a have no elements in common.
+func (s *OrderedSet[T]) IsDisjoint(a *OrderedSet[T]) bool {
+	small, large := s, a
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+
+	for elem := range small.elements {
+		if _, ok := large.elements[elem]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalJSON encodes the set as a JSON array, in order.
+func (s *OrderedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Values())
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of the decoded
+// JSON array, in order. Duplicates in the array are ignored.
+func (s *OrderedSet[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	s.Clear()
+	s.Add(values...)
+	return nil
+}
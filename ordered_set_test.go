@@ -0,0 +1,71 @@
+package zdutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	set := NewOrderedSet(3, 1, 2, 1, 5)
+
+	want := []int{3, 1, 2, 5}
+	got := set.Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to keep insertion order:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	set.Remove(1)
+	set.MoveToFront(5)
+	set.MoveToBack(3)
+	set.Add(4)
+
+	want = []int{5, 2, 3, 4}
+	got = set.Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to move elements:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	for i := range want {
+		if elem, ok := set.At(i); !ok || elem != want[i] || set.IndexOf(want[i]) != i {
+			fmt.Printf("[ERROR] failed to index element:\n\t[index=%d]\n\t[got=%v]\n\t[want=%v]\n", i, elem, want[i])
+			t.FailNow()
+		}
+	}
+
+	if _, ok := set.At(len(want)); ok || set.IndexOf(1) != -1 {
+		fmt.Println("[ERROR] expected missing index and element to be reported")
+		t.FailNow()
+	}
+
+	other := NewOrderedSet(4, 6, 2)
+
+	want = []int{5, 2, 3, 4, 6}
+	got = set.Union(other).Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to union ordered sets:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	want = []int{2, 4}
+	got = set.Intersect(other).Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to intersect ordered sets:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	want = []int{5, 3, 6}
+	got = set.SymmetricDifference(other).Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to get symmetric difference of ordered sets:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil || string(data) != "[5,2,3,4]" {
+		fmt.Printf("[ERROR] failed to marshal ordered set:\n\t[got=%s]\n\t[error=%v]\n", data, err)
+		t.FailNow()
+	}
+}
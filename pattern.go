@@ -0,0 +1,184 @@
+package zdutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pathPattern is a single compiled gitignore pattern.
+type pathPattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// PathMatcher matches slash separated paths, relative to some root, against a list
+// of patterns with the semantics of a .gitignore file:
+//
+//   - blank lines and lines starting with "#" are ignored, "\#" and "\!" escape them
+//   - "*", "?" and "[...]" match within a single path segment, as in path.Match
+//   - "**" matches any number of segments, as in "**/build", "logs/**" or "a/**/b"
+//   - a pattern without a slash, other than a trailing one, matches at any depth,
+//     while any other pattern is anchored to the root
+//   - a trailing "/" only matches directories
+//   - a leading "!" re-includes paths excluded by an earlier pattern, and the last
+//     matching pattern wins
+//   - a path inside a matched directory is matched too, and cannot be re-included
+//
+// The zero value matches nothing.
+type PathMatcher struct {
+	patterns []pathPattern
+}
+
+// NewPathMatcher compiles the given patterns, in order, into a PathMatcher.
+// It returns an error if any of the patterns is malformed.
+func NewPathMatcher(patterns ...string) (*PathMatcher, error) {
+	m := &PathMatcher{}
+	if err := m.add(patterns, true); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReadPathMatcher compiles a PathMatcher from the lines of r, such as a .gitignore file.
+func ReadPathMatcher(r io.Reader) (*PathMatcher, error) {
+	var patterns []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewPathMatcher(patterns...)
+}
+
+// add compiles and appends the patterns. If strict is false, malformed segments
+// are kept and compared literally instead of returning an error.
+func (m *PathMatcher) add(patterns []string, strict bool) error {
+	for _, raw := range patterns {
+		// Trailing spaces are dropped unless the last one is escaped with a backslash.
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimRight(line, " ")
+		if strings.HasSuffix(trimmed, "\\") && len(trimmed) < len(line) {
+			trimmed = trimmed[:len(trimmed)-1] + " "
+		}
+		line = trimmed
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p pathPattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		// A slash at the start or in the middle anchors the pattern to the root.
+		if !strings.Contains(line, "/") {
+			p.segments = append(p.segments, "**")
+		}
+		n := len(p.segments)
+		for _, seg := range strings.Split(strings.TrimPrefix(line, "/"), "/") {
+			if seg == "" {
+				continue
+			}
+			if _, err := path.Match(seg, ""); err != nil && strict {
+				return fmt.Errorf("malformed path pattern [pattern=%s]: %w", raw, err)
+			}
+			p.segments = append(p.segments, seg)
+		}
+
+		if len(p.segments) == n {
+			continue
+		}
+		m.patterns = append(m.patterns, p)
+	}
+
+	return nil
+}
+
+// Match reports whether the path rel, relative to the root of the patterns, is
+// matched. isDir tells whether rel is a directory, for directory-only patterns.
+// Both "/" and the OS path separator are accepted, and leading or trailing slashes
+// are ignored.
+func (m *PathMatcher) Match(rel string, isDir bool) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	if rel == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(parts[:i], true) {
+			return true
+		}
+	}
+
+	return m.match(parts, isDir)
+}
+
+// match applies every pattern to parts, without considering its parent directories.
+func (m *PathMatcher) match(parts []string, isDir bool) bool {
+	matched := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(p.segments, parts) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// matchSegments reports whether the pattern segments match all of the path segments.
+// A trailing "**" requires at least one more segment, so "logs/**" does not match "logs".
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(parts) > 0
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(parts) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], parts[0])
+		if err != nil {
+			ok = pattern[0] == parts[0]
+		}
+		if !ok {
+			return false
+		}
+
+		pattern, parts = pattern[1:], parts[1:]
+	}
+
+	return len(parts) == 0
+}
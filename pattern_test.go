@@ -0,0 +1,63 @@
+package zdutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPathMatcher(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{patterns: []string{"*.log"}, path: "debug.log", want: true},
+		{patterns: []string{"*.log"}, path: "logs/a/debug.log", want: true},
+		{patterns: []string{"*.log"}, path: "debug.log.txt", want: false},
+		{patterns: []string{"/debug.log"}, path: "logs/debug.log", want: false},
+		{patterns: []string{"logs/debug.log"}, path: "logs/debug.log", want: true},
+		{patterns: []string{"logs/debug.log"}, path: "a/logs/debug.log", want: false},
+		{patterns: []string{"build/"}, path: "build", want: false},
+		{patterns: []string{"build/"}, path: "build", isDir: true, want: true},
+		{patterns: []string{"build/"}, path: "src/build/out.o", want: true},
+		{patterns: []string{"**/cache"}, path: "a/b/cache", isDir: true, want: true},
+		{patterns: []string{"logs/**"}, path: "logs", isDir: true, want: false},
+		{patterns: []string{"logs/**"}, path: "logs/a/b.txt", want: true},
+		{patterns: []string{"a/**/b"}, path: "a/b", want: true},
+		{patterns: []string{"a/**/b"}, path: "a/x/y/b", want: true},
+		{patterns: []string{"file?.[ch]"}, path: "dir/file1.c", want: true},
+		{patterns: []string{"*.log", "!keep.log"}, path: "keep.log", want: false},
+		{patterns: []string{"*.log", "!keep.log", "*.log"}, path: "keep.log", want: true},
+		{patterns: []string{"build/", "!build/keep.txt"}, path: "build/keep.txt", want: true},
+		{patterns: []string{"build/*", "!build/keep.txt"}, path: "build/keep.txt", want: false},
+		{patterns: []string{"# comment", "", `\#hash`}, path: "#hash", want: true},
+		{patterns: []string{`\!bang`}, path: "!bang", want: true},
+		{patterns: []string{"trailing   "}, path: "trailing", want: true},
+	}
+
+	for _, tc := range tests {
+		m, err := NewPathMatcher(tc.patterns...)
+		if err != nil {
+			fmt.Printf("[ERROR] failed to compile patterns:\n\t[patterns=%v]\n\t[error=%v]\n", tc.patterns, err)
+			t.FailNow()
+		}
+
+		if got := m.Match(tc.path, tc.isDir); got != tc.want {
+			fmt.Printf("[ERROR] unexpected match:\n\t[patterns=%v]\n\t[path=%s]\n\t[dir=%t]\n\t[got=%t]\n", tc.patterns, tc.path, tc.isDir, got)
+			t.FailNow()
+		}
+	}
+
+	if _, err := NewPathMatcher("[a-"); err == nil {
+		fmt.Printf("[ERROR] expected malformed pattern to fail\n")
+		t.FailNow()
+	}
+
+	m, err := ReadPathMatcher(strings.NewReader("# ignore\nvendor/\n*.tmp\n!important.tmp\n"))
+	if err != nil || !m.Match("vendor/x.go", false) || !m.Match("a.tmp", false) || m.Match("important.tmp", false) {
+		fmt.Printf("[ERROR] failed to read patterns:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+}
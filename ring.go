@@ -0,0 +1,273 @@
+package zdutil
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RingBuffer is a fixed size buffer that overwrites its oldest element when full.
+// It is not safe for concurrent use, see SyncRingBuffer for a thread-safe variant.
+type RingBuffer[T any] struct {
+	buf   []T
+	start int
+	size  int
+}
+
+// NewRingBuffer creates an empty RingBuffer that holds at most capacity elements.
+// A capacity less than 1 is treated as 1.
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &RingBuffer[T]{buf: make([]T, capacity)}
+}
+
+// Push adds one or more elements to the buffer, in order.
+// Once the buffer is full each new element overwrites the oldest one.
+func (r *RingBuffer[T]) Push(elem ...T) {
+	for i := range elem {
+		if r.size < len(r.buf) {
+			r.buf[(r.start+r.size)%len(r.buf)] = elem[i]
+			r.size++
+			continue
+		}
+
+		r.buf[r.start] = elem[i]
+		r.start = (r.start + 1) % len(r.buf)
+	}
+}
+
+// Peek returns the most recently pushed element without removing it.
+// The function assumes that the buffer is not empty.
+func (r *RingBuffer[T]) Peek() T {
+	if r.size == 0 {
+		panic("zdutil: Peek called on empty RingBuffer")
+	}
+
+	return r.buf[(r.start+r.size-1)%len(r.buf)]
+}
+
+// Pop removes and returns the most recently pushed element.
+// The function returns nil if the buffer is empty.
+func (r *RingBuffer[T]) Pop() *T {
+	if r.size == 0 {
+		return nil
+	}
+
+	var zero T
+	i := (r.start + r.size - 1) % len(r.buf)
+	elem := r.buf[i]
+	r.buf[i] = zero
+	r.size--
+
+	return &elem
+}
+
+// Last returns up to n of the most recently pushed elements, ordered from oldest to newest.
+func (r *RingBuffer[T]) Last(n int) []T {
+	if n > r.size {
+		n = r.size
+	}
+	if n <= 0 {
+		return []T{}
+	}
+
+	res := make([]T, n)
+	offset := r.start + r.size - n
+	for i := range res {
+		res[i] = r.buf[(offset+i)%len(r.buf)]
+	}
+
+	return res
+}
+
+// Snapshot returns a copy of every element in the buffer, ordered from oldest to newest.
+func (r *RingBuffer[T]) Snapshot() []T {
+	return r.Last(r.size)
+}
+
+// Len returns the number of elements in the buffer.
+func (r *RingBuffer[T]) Len() int {
+	return r.size
+}
+
+// Cap returns the maximum number of elements the buffer can hold.
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.buf)
+}
+
+// Clear removes all elements from the buffer, leaving it empty.
+func (r *RingBuffer[T]) Clear() {
+	r.buf = make([]T, len(r.buf))
+	r.start = 0
+	r.size = 0
+}
+
+// Each calls fn for every element from the oldest to the newest,
+// until fn returns false.
+func (r *RingBuffer[T]) Each(fn func(T) bool) {
+	for i := 0; i < r.size; i++ {
+		if !fn(r.buf[(r.start+i)%len(r.buf)]) {
+			return
+		}
+	}
+}
+
+// String returns a string representation of the buffer.
+// The string is formatted as "[x,y,z]" where x is the oldest and z the newest element.
+func (r *RingBuffer[T]) String() string {
+	if r.size <= 0 {
+		return "[]"
+	}
+
+	var str string
+	str = "["
+	for _, e := range r.Snapshot() {
+		str += fmt.Sprint(e, ",")
+	}
+	str = str[:len(str)-1]
+	str += "]"
+	return str
+}
+
+// SyncRingBuffer is a RingBuffer that is safe for concurrent use.
+type SyncRingBuffer[T any] struct {
+	ring *RingBuffer[T]
+	m    sync.Mutex
+}
+
+// NewSyncRingBuffer creates an empty SyncRingBuffer that holds at most capacity elements.
+// A capacity less than 1 is treated as 1.
+func NewSyncRingBuffer[T any](capacity int) *SyncRingBuffer[T] {
+	return &SyncRingBuffer[T]{ring: NewRingBuffer[T](capacity)}
+}
+
+// Push adds one or more elements to the buffer, in order.
+// Once the buffer is full each new element overwrites the oldest one.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Push(elem ...T) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.ring.Push(elem...)
+}
+
+// Peek returns the most recently pushed element without removing it.
+// The function assumes that the buffer is not empty.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Peek() T {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.ring.Peek()
+}
+
+// Pop removes and returns the most recently pushed element.
+// The function returns nil if the buffer is empty.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Pop() *T {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.ring.Pop()
+}
+
+// Last returns up to n of the most recently pushed elements, ordered from oldest to newest.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Last(n int) []T {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.ring.Last(n)
+}
+
+// Snapshot returns a copy of every element in the buffer, ordered from oldest to newest.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Snapshot() []T {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.ring.Snapshot()
+}
+
+// Len returns the number of elements in the buffer.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Len() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.ring.Len()
+}
+
+// Cap returns the maximum number of elements the buffer can hold.
+func (r *SyncRingBuffer[T]) Cap() int {
+	return r.ring.Cap()
+}
+
+// Clear removes all elements from the buffer, leaving it empty.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) Clear() {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.ring.Clear()
+}
+
+// Each calls fn for every element from the oldest to the newest, until fn
+// returns false. It iterates over a snapshot taken under the lock, so fn may
+// safely modify the buffer.
+func (r *SyncRingBuffer[T]) Each(fn func(T) bool) {
+	for _, elem := range r.Snapshot() {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// String returns a string representation of the buffer.
+// The string is formatted as "[x,y,z]" where x is the oldest and z the newest element.
+// It acquires a lock to ensure thread-safe access.
+func (r *SyncRingBuffer[T]) String() string {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.ring.String()
+}
+
+// ByteRingBuffer is a thread-safe ring buffer of bytes that implements io.Writer,
+// keeping only the most recently written bytes. It is useful for rolling logs.
+type ByteRingBuffer struct {
+	*SyncRingBuffer[byte]
+}
+
+// NewByteRingBuffer creates an empty ByteRingBuffer that keeps at most capacity bytes.
+// A capacity less than 1 is treated as 1.
+func NewByteRingBuffer(capacity int) *ByteRingBuffer {
+	return &ByteRingBuffer{SyncRingBuffer: NewSyncRingBuffer[byte](capacity)}
+}
+
+// Write appends p to the buffer, overwriting the oldest bytes once it is full.
+// It always reports the full length of p as written and never returns an error.
+func (b *ByteRingBuffer) Write(p []byte) (int, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if len(p) > b.ring.Cap() {
+		b.ring.Push(p[len(p)-b.ring.Cap():]...)
+	} else {
+		b.ring.Push(p...)
+	}
+
+	return len(p), nil
+}
+
+// Bytes returns a copy of the bytes in the buffer, ordered from oldest to newest.
+func (b *ByteRingBuffer) Bytes() []byte {
+	return b.Snapshot()
+}
+
+// String returns the contents of the buffer as a string.
+func (b *ByteRingBuffer) String() string {
+	return string(b.Bytes())
+}
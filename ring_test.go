@@ -0,0 +1,60 @@
+package zdutil
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	ring := NewRingBuffer[int](3)
+	ring.Push(1, 2, 3, 4, 5)
+
+	want := []int{3, 4, 5}
+	got := ring.Snapshot()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to overwrite oldest elements:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	want = []int{4, 5}
+	got = ring.Last(2)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to get last elements:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	if elem := ring.Pop(); elem == nil || *elem != 5 || ring.Peek() != 4 || ring.Len() != 2 {
+		fmt.Printf("[ERROR] failed to pop newest element:\n\t[got=%v]\n", ring.String())
+		t.FailNow()
+	}
+
+	ring.Push(6, 7)
+	if ring.String() != "[4,6,7]" {
+		fmt.Printf("[ERROR] failed to wrap around:\n\t[got=%s]\n\t[want=%s]\n", ring.String(), "[4,6,7]")
+		t.FailNow()
+	}
+}
+
+func TestByteRingBuffer(t *testing.T) {
+	var w io.Writer = NewByteRingBuffer(8)
+
+	fmt.Fprint(w, "hello ")
+	fmt.Fprint(w, "world")
+	fmt.Fprint(w, "")
+
+	want := "lo world"
+	got := w.(*ByteRingBuffer).String()
+	if got != want {
+		fmt.Printf("[ERROR] failed to keep last written bytes:\n\t[got=%s]\n\t[want=%s]\n", got, want)
+		t.FailNow()
+	}
+
+	fmt.Fprint(w, "a long line that overflows the buffer")
+	want = "e buffer"
+	got = w.(*ByteRingBuffer).String()
+	if got != want {
+		fmt.Printf("[ERROR] failed to keep last written bytes:\n\t[got=%s]\n\t[want=%s]\n", got, want)
+		t.FailNow()
+	}
+}
@@ -1,5 +1,7 @@
 package zdutil
 
+import "encoding/json"
+
 type Set[T comparable] struct {
 	elements map[T]struct{}
 }
@@ -23,6 +25,10 @@ func NewSet[T comparable](elems ...T) *Set[T] {
 //
 // The function is a no-op if any of the specified elements already exist in the set.
 func (s *Set[T]) Add(elems ...T) {
+	if s.elements == nil {
+		s.elements = make(map[T]struct{}, len(elems))
+	}
+
 	for i := range elems {
 		s.elements[elems[i]] = struct{}{}
 	}
@@ -72,103 +78,384 @@ func (s *Set[T]) Values() []T {
 	return keys
 }
 
-// Intersect returns a new set that is the intersection of the two sets.
-// The function creates a new set and adds all elements from the two sets
-// to the new set. The function ignores duplicates.
+// ToMap returns the elements of the set as a new map[T]struct{}.
+func (s *Set[T]) ToMap() map[T]struct{} {
+	m := make(map[T]struct{}, len(s.elements))
+	for elem := range s.elements {
+		m[elem] = struct{}{}
+	}
+	return m
+}
+
+// Each calls fn for every element in the set, in unspecified order,
+// until fn returns false.
+func (s *Set[T]) Each(fn func(T) bool) {
+	for elem := range s.elements {
+		if !fn(elem) {
+			return
+		}
+	}
+}
+
+// Intersect returns a new set containing the elements that exist in both s and a.
+// It iterates over the smaller of the two sets.
 func (s *Set[T]) Intersect(a Set[T]) Set[T] {
-	intersect := NewSet[T]()
+	small, large := s, &a
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
 
-	values := a.Values()
-	for i := range values {
-		if s.Contains(values[i]) {
-			intersect.Add(values[i])
+	intersect := NewSet[T]()
+	for elem := range small.elements {
+		if _, ok := large.elements[elem]; ok {
+			intersect.elements[elem] = struct{}{}
 		}
 	}
 
 	return *intersect
 }
 
-// Union returns a new set that is the union of the two sets. The function
-// creates a new set and adds all elements from both sets to the new set.
-// The function ignores duplicates.
+// Union returns a new set containing the elements that exist in s, in a, or in both.
 func (s *Set[T]) Union(a Set[T]) Set[T] {
 	union := NewSet[T]()
 
-	union.Add(a.Values()...)
+	for elem := range s.elements {
+		union.elements[elem] = struct{}{}
+	}
+	for elem := range a.elements {
+		union.elements[elem] = struct{}{}
+	}
 
 	return *union
 }
 
-// ExceptRight returns elements of the first set that do not exist in the second set.
-// Ignores duplicates.
+// ExceptRight returns a new set containing the elements of s that do not exist in a,
+// that is the difference s - a.
 func (s *Set[T]) ExceptRight(a Set[T]) Set[T] {
 	except := NewSet[T]()
 
-	values := a.Values()
-	for i := range values {
-		if !s.Contains(values[i]) {
-			except.Add(values[i])
+	for elem := range s.elements {
+		if _, ok := a.elements[elem]; !ok {
+			except.elements[elem] = struct{}{}
 		}
 	}
 
 	return *except
 }
 
-// ExceptLeft returns elements of the first set that do not exist in the second set.
-// Ignores duplicates.
+// ExceptLeft returns a new set containing the elements of a that do not exist in s,
+// that is the difference a - s.
 func (s *Set[T]) ExceptLeft(a Set[T]) Set[T] {
 	return a.ExceptRight(*s)
 }
 
-// Intersect returns a slice containing elements that are present in both input slices a and b.
-// The function maintains the order of elements as they appear in slice b and does not include duplicates.
+// UnionAll returns a new set containing the elements that exist in any of the given sets.
+func UnionAll[T comparable](sets ...*Set[T]) *Set[T] {
+	size := 0
+	for _, set := range sets {
+		if set.Len() > size {
+			size = set.Len()
+		}
+	}
+
+	union := &Set[T]{elements: make(map[T]struct{}, size)}
+	for _, set := range sets {
+		for elem := range set.elements {
+			union.elements[elem] = struct{}{}
+		}
+	}
+
+	return union
+}
+
+// IntersectAll returns a new set containing the elements that exist in every one of
+// the given sets. It starts from the smallest set, removes the elements missing from
+// each other set and stops as soon as the result becomes empty.
+// Calling it without any sets returns an empty set.
+func IntersectAll[T comparable](sets ...*Set[T]) *Set[T] {
+	if len(sets) == 0 {
+		return NewSet[T]()
+	}
 
-func Intersect[T comparable](a, b []T) []T {
-	var intersect []T
+	smallest := 0
+	for i := range sets {
+		if sets[i].Len() < sets[smallest].Len() {
+			smallest = i
+		}
+	}
 
-	commonMap := make(map[T]bool)
+	intersect := &Set[T]{elements: make(map[T]struct{}, sets[smallest].Len())}
+	for elem := range sets[smallest].elements {
+		intersect.elements[elem] = struct{}{}
+	}
 
-	for i := range a {
-		commonMap[a[i]] = true
+	for i := range sets {
+		if i == smallest {
+			continue
+		}
+		if intersect.Len() == 0 {
+			break
+		}
+
+		for elem := range intersect.elements {
+			if _, ok := sets[i].elements[elem]; !ok {
+				delete(intersect.elements, elem)
+			}
+		}
+	}
+
+	return intersect
+}
+
+// SymmetricDifference returns a new set containing the elements that exist in
+// exactly one of s and a.
+func (s *Set[T]) SymmetricDifference(a Set[T]) Set[T] {
+	diff := NewSet[T]()
+
+	for elem := range s.elements {
+		if _, ok := a.elements[elem]; !ok {
+			diff.elements[elem] = struct{}{}
+		}
+	}
+	for elem := range a.elements {
+		if _, ok := s.elements[elem]; !ok {
+			diff.elements[elem] = struct{}{}
+		}
+	}
+
+	return *diff
+}
+
+// IsSubset reports whether every element of s exists in a.
+func (s *Set[T]) IsSubset(a Set[T]) bool {
+	if s.Len() > a.Len() {
+		return false
+	}
+
+	for elem := range s.elements {
+		if _, ok := a.elements[elem]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSuperset reports whether every element of a exists in s.
+func (s *Set[T]) IsSuperset(a Set[T]) bool {
+	return a.IsSubset(*s)
+}
+
+// IsProperSubset reports whether s is a subset of a and a has at least one
+// element that does not exist in s.
+func (s *Set[T]) IsProperSubset(a Set[T]) bool {
+	return s.Len() < a.Len() && s.IsSubset(a)
+}
+
+// Equal reports whether s and a contain exactly the same elements.
+func (s *Set[T]) Equal(a Set[T]) bool {
+	return s.Len() == a.Len() && s.IsSubset(a)
+}
+
+// IsDisjoint reports whether s and a have no elements in common.
+// It iterates over the smaller of the two sets.
+func (s *Set[T]) IsDisjoint(a Set[T]) bool {
+	small, large := s, &a
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+
+	for elem := range small.elements {
+		if _, ok := large.elements[elem]; ok {
+			return false
+		}
 	}
 
+	return true
+}
+
+// sortedValues returns all elements in the set, sorted when T is an ordered kind
+// so that encoded output is deterministic.
+func (s Set[T]) sortedValues() []T {
+	values := s.Values()
+	sortValues(values)
+	return values
+}
+
+// MarshalJSON encodes the set as a JSON array. When T is an integer, float or
+// string type the array is sorted, otherwise the order is unspecified.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.sortedValues())
+}
+
+// UnmarshalJSON replaces the contents of the set with the elements of the
+// decoded JSON array. Duplicates in the array are ignored.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*s = *NewSet(values...)
+	return nil
+}
+
+// GobEncode encodes the set as a gob stream, sorted in the same way as MarshalJSON.
+func (s Set[T]) GobEncode() ([]byte, error) {
+	return gobEncodeValues(s.sortedValues())
+}
+
+// GobDecode replaces the contents of the set with the decoded gob stream.
+func (s *Set[T]) GobDecode(data []byte) error {
+	values, err := gobDecodeValues[T](data)
+	if err != nil {
+		return err
+	}
+
+	*s = *NewSet(values...)
+	return nil
+}
+
+// MarshalText encodes the set as "[x,y,z]", sorted in the same way as MarshalJSON.
+// It returns an error if T is not a string, boolean, number or encoding.TextMarshaler.
+func (s Set[T]) MarshalText() ([]byte, error) {
+	return marshalTextValues(s.sortedValues())
+}
+
+// The slice functions below mirror the Set algebra: each result contains distinct
+// elements only, ordered by their first appearance in a and then in b.
+
+// Intersect returns the distinct elements that are present in both slices a and b,
+// in the order they first appear in a.
+func Intersect[T comparable](a, b []T) []T {
+	var intersect []T
+
+	inB := make(map[T]struct{}, len(b))
 	for i := range b {
-		if commonMap[b[i]] {
-			intersect = append(intersect, b[i])
+		inB[b[i]] = struct{}{}
+	}
+
+	seen := make(map[T]struct{}, len(a))
+	for i := range a {
+		if _, ok := inB[a[i]]; !ok {
+			continue
+		}
+		if _, ok := seen[a[i]]; ok {
+			continue
 		}
+
+		seen[a[i]] = struct{}{}
+		intersect = append(intersect, a[i])
 	}
 
 	return intersect
 }
 
-// Union returns a slice containing all elements from the input slices a and b.
-// The function includes duplicates.
+// Union returns the distinct elements that are present in a, in b, or in both,
+// in the order they first appear in a and then in b.
 func Union[T comparable](a, b []T) []T {
-	return append(a, b...)
-}
+	var union []T
 
-// ExceptRight returns elements of the first array that do not exist in the second array.
-// Ignores duplicates and the order of elements in the second array.
+	seen := make(map[T]struct{}, len(a)+len(b))
+	for _, slice := range [][]T{a, b} {
+		for i := range slice {
+			if _, ok := seen[slice[i]]; ok {
+				continue
+			}
 
+			seen[slice[i]] = struct{}{}
+			union = append(union, slice[i])
+		}
+	}
+
+	return union
+}
+
+// ExceptRight returns the distinct elements of a that do not exist in b,
+// in the order they first appear in a.
 func ExceptRight[T comparable](a, b []T) []T {
 	var except []T
 
-	commonMap := make(map[T]bool)
-
+	seen := make(map[T]struct{}, len(a)+len(b))
 	for i := range b {
-		commonMap[b[i]] = true
+		seen[b[i]] = struct{}{}
 	}
 
 	for i := range a {
-		if !commonMap[a[i]] {
-			except = append(except, a[i])
+		if _, ok := seen[a[i]]; ok {
+			continue
 		}
+
+		seen[a[i]] = struct{}{}
+		except = append(except, a[i])
 	}
 
 	return except
 }
 
-// ExceptLeft returns elements of a that do not exist in b. Ignores duplicates.
+// ExceptLeft returns the distinct elements of b that do not exist in a,
+// in the order they first appear in b.
 func ExceptLeft[T comparable](a, b []T) []T {
 	return ExceptRight(b, a)
 }
+
+// SymmetricDifference returns the distinct elements that are present in exactly one
+// of the slices a and b, in the order they first appear in a and then in b.
+func SymmetricDifference[T comparable](a, b []T) []T {
+	return append(ExceptRight(a, b), ExceptLeft(a, b)...)
+}
+
+// IsSubset reports whether every element of a exists in b.
+func IsSubset[T comparable](a, b []T) bool {
+	inB := make(map[T]struct{}, len(b))
+	for i := range b {
+		inB[b[i]] = struct{}{}
+	}
+
+	for i := range a {
+		if _, ok := inB[a[i]]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSuperset reports whether every element of b exists in a.
+func IsSuperset[T comparable](a, b []T) bool {
+	return IsSubset(b, a)
+}
+
+// IsProperSubset reports whether a is a subset of b and b has at least one
+// element that does not exist in a. Duplicates are ignored.
+func IsProperSubset[T comparable](a, b []T) bool {
+	return IsSubset(a, b) && !IsSubset(b, a)
+}
+
+// Equal reports whether a and b contain the same distinct elements,
+// ignoring order and duplicates.
+func Equal[T comparable](a, b []T) bool {
+	return IsSubset(a, b) && IsSubset(b, a)
+}
+
+// IsDisjoint reports whether a and b have no elements in common.
+func IsDisjoint[T comparable](a, b []T) bool {
+	// Only the smaller slice is put in a map, and the other is scanned until the
+	// first common element.
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	inA := make(map[T]struct{}, len(a))
+	for i := range a {
+		inA[a[i]] = struct{}{}
+	}
+
+	for i := range b {
+		if _, ok := inA[b[i]]; ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package zdutil
+
+// The functions below operate directly on the elements of a Set. They carry a
+// Set suffix so they do not collide with the slice helpers: Partition and GroupBy
+// in slice.go take slices, and Filter, Reduce, Any and All are left free for the
+// slice versions in the same way.
+
+// FilterSet returns a new set containing the elements of s for which pred returns true.
+func FilterSet[T comparable](s *Set[T], pred func(T) bool) *Set[T] {
+	filtered := NewSet[T]()
+	for elem := range s.elements {
+		if pred(elem) {
+			filtered.elements[elem] = struct{}{}
+		}
+	}
+	return filtered
+}
+
+// MapSet returns a new set containing fn applied to every element of s.
+// Elements that map to the same value are stored once.
+func MapSet[T, U comparable](s *Set[T], fn func(T) U) *Set[U] {
+	mapped := &Set[U]{elements: make(map[U]struct{}, s.Len())}
+	for elem := range s.elements {
+		mapped.elements[fn(elem)] = struct{}{}
+	}
+	return mapped
+}
+
+// ReduceSet folds every element of s into an accumulator, starting from initial.
+// The elements are visited in unspecified order, so fn should be commutative.
+func ReduceSet[T comparable, A any](s *Set[T], initial A, fn func(A, T) A) A {
+	acc := initial
+	for elem := range s.elements {
+		acc = fn(acc, elem)
+	}
+	return acc
+}
+
+// AnySet reports whether pred returns true for at least one element of s.
+// It returns false for an empty set.
+func AnySet[T comparable](s *Set[T], pred func(T) bool) bool {
+	for elem := range s.elements {
+		if pred(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllSet reports whether pred returns true for every element of s.
+// It returns true for an empty set.
+func AllSet[T comparable](s *Set[T], pred func(T) bool) bool {
+	for elem := range s.elements {
+		if !pred(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+// PartitionSet splits s into the set of elements for which pred returns true
+// and the set of elements for which it returns false.
+func PartitionSet[T comparable](s *Set[T], pred func(T) bool) (matched, unmatched *Set[T]) {
+	matched, unmatched = NewSet[T](), NewSet[T]()
+	for elem := range s.elements {
+		if pred(elem) {
+			matched.elements[elem] = struct{}{}
+		} else {
+			unmatched.elements[elem] = struct{}{}
+		}
+	}
+	return matched, unmatched
+}
+
+// GroupBySet splits s into sets keyed by the result of key for each element.
+func GroupBySet[T comparable, K comparable](s *Set[T], key func(T) K) map[K]*Set[T] {
+	groups := make(map[K]*Set[T])
+	for elem := range s.elements {
+		k := key(elem)
+		group, ok := groups[k]
+		if !ok {
+			group = NewSet[T]()
+			groups[k] = group
+		}
+		group.elements[elem] = struct{}{}
+	}
+	return groups
+}
@@ -1,8 +1,10 @@
 package zdutil
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"testing/quick"
 )
 
 func TestIntersect(t *testing.T) {
@@ -65,7 +67,7 @@ func TestExcept(t *testing.T) {
 	rightset := NewSet(right...)
 
 	want = []int{1, 4, 7}
-	gotset := rightset.ExceptRight(*leftset)
+	gotset := leftset.ExceptRight(*rightset)
 
 	if gotset.Len() < len(want) {
 		fmt.Printf("[ERROR] failed to get correct exception:\n\t[got=%v]\n\t[want=%v]\n", gotset.Values(), want)
@@ -77,7 +79,7 @@ func TestExcept(t *testing.T) {
 		t.FailNow()
 	}
 
-	want = []int{0, 5, 5, 8, 9}
+	want = []int{0, 5, 8, 9}
 	got = ExceptLeft(left, right)
 
 	if len(got) < len(want) {
@@ -94,7 +96,7 @@ func TestExcept(t *testing.T) {
 	}
 
 	want = []int{0, 5, 8, 9}
-	gotset = rightset.ExceptLeft(*leftset)
+	gotset = leftset.ExceptLeft(*rightset)
 
 	if gotset.Len() < len(want) {
 		fmt.Printf("[ERROR] failed to get correct exception:\n\t[got=%v]\n\t[want=%v]\n", gotset.Values(), want)
@@ -106,7 +108,7 @@ func TestExcept(t *testing.T) {
 		t.FailNow()
 	}
 
-	want = []int{0, 5, 5, 8, 9, 1, 4, 7}
+	want = []int{0, 5, 8, 9, 1, 4, 7}
 	got = Union(ExceptLeft(left, right), ExceptRight(left, right))
 
 	if len(got) < len(want) {
@@ -122,3 +124,190 @@ func TestExcept(t *testing.T) {
 		}
 	}
 }
+
+func TestSetEncoding(t *testing.T) {
+	set := NewSet(5, 3, 9, 1)
+
+	want := "[1,3,5,9]"
+	data, err := json.Marshal(set)
+	if err != nil || string(data) != want {
+		fmt.Printf("[ERROR] failed to marshal set to json:\n\t[got=%s]\n\t[want=%s]\n\t[error=%v]\n", data, want, err)
+		t.FailNow()
+	}
+
+	var gotset Set[int]
+	if err := json.Unmarshal(data, &gotset); err != nil || gotset.Len() != set.Len() || !gotset.Contains(set.Values()...) {
+		fmt.Printf("[ERROR] failed to unmarshal set from json:\n\t[got=%v]\n\t[want=%v]\n\t[error=%v]\n", gotset.Values(), set.Values(), err)
+		t.FailNow()
+	}
+
+	data, err = set.GobEncode()
+	if err != nil {
+		fmt.Printf("[ERROR] failed to gob encode set:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	gotset = Set[int]{}
+	if err := gotset.GobDecode(data); err != nil || gotset.Len() != set.Len() || !gotset.Contains(set.Values()...) {
+		fmt.Printf("[ERROR] failed to gob decode set:\n\t[got=%v]\n\t[want=%v]\n\t[error=%v]\n", gotset.Values(), set.Values(), err)
+		t.FailNow()
+	}
+
+	data, err = set.MarshalText()
+	if err != nil || string(data) != want {
+		fmt.Printf("[ERROR] failed to marshal set to text:\n\t[got=%s]\n\t[want=%s]\n\t[error=%v]\n", data, want, err)
+		t.FailNow()
+	}
+
+	if _, err := NewSet([2]int{1, 2}).MarshalText(); err == nil {
+		fmt.Println("[ERROR] expected error when marshaling unsupported element type to text")
+		t.FailNow()
+	}
+}
+
+func sameSet[T comparable](a, b Set[T]) bool {
+	return a.Len() == b.Len() && (a.Len() == 0 || a.Contains(b.Values()...))
+}
+
+func distinct[T comparable](slice []T) bool {
+	return len(slice) == NewSet(slice...).Len()
+}
+
+func TestSetAlgebraProperties(t *testing.T) {
+	// int8 keeps the domain small so that random sets overlap often
+	properties := map[string]interface{}{
+		"union is commutative": func(a, b []int8) bool {
+			sa, sb := NewSet(a...), NewSet(b...)
+			return sameSet(sa.Union(*sb), sb.Union(*sa))
+		},
+		"intersect is commutative": func(a, b []int8) bool {
+			sa, sb := NewSet(a...), NewSet(b...)
+			return sameSet(sa.Intersect(*sb), sb.Intersect(*sa))
+		},
+		"de morgan over union": func(u, a, b []int8) bool {
+			su, sa, sb := NewSet(u...), NewSet(a...), NewSet(b...)
+			union := sa.Union(*sb)
+			left := su.ExceptRight(union)
+			right := su.ExceptRight(*sa)
+			return sameSet(left, right.Intersect(su.ExceptRight(*sb)))
+		},
+		"de morgan over intersect": func(u, a, b []int8) bool {
+			su, sa, sb := NewSet(u...), NewSet(a...), NewSet(b...)
+			intersect := sa.Intersect(*sb)
+			left := su.ExceptRight(intersect)
+			right := su.ExceptRight(*sa)
+			return sameSet(left, right.Union(su.ExceptRight(*sb)))
+		},
+		"identity laws": func(a []int8) bool {
+			sa, empty := NewSet(a...), NewSet[int8]()
+			return sameSet(sa.Union(*empty), *sa) &&
+				sameSet(sa.Intersect(*empty), *empty) &&
+				sameSet(sa.Intersect(*sa), *sa) &&
+				sameSet(sa.ExceptRight(*empty), *sa) &&
+				sameSet(sa.ExceptRight(*sa), *empty)
+		},
+		"except left mirrors except right": func(a, b []int8) bool {
+			sa, sb := NewSet(a...), NewSet(b...)
+			return sameSet(sa.ExceptLeft(*sb), sb.ExceptRight(*sa))
+		},
+		"relations agree with algebra": func(a, b []int8) bool {
+			sa, sb := NewSet(a...), NewSet(b...)
+			union, intersect, except := sa.Union(*sb), sa.Intersect(*sb), sa.ExceptRight(*sb)
+			return sa.IsSubset(union) && union.IsSuperset(*sa) &&
+				intersect.IsSubset(*sa) &&
+				sa.IsSubset(*sb) == (except.Len() == 0) &&
+				sa.IsProperSubset(*sb) == (sa.IsSubset(*sb) && !sa.Equal(*sb)) &&
+				sa.IsDisjoint(*sb) == (intersect.Len() == 0) &&
+				sameSet(sa.SymmetricDifference(*sb), union.ExceptRight(intersect))
+		},
+		"slice relations agree with sets": func(a, b []int8) bool {
+			sa, sb := NewSet(a...), NewSet(b...)
+			diff := SymmetricDifference(a, b)
+			return IsSubset(a, b) == sa.IsSubset(*sb) &&
+				IsSuperset(a, b) == sa.IsSuperset(*sb) &&
+				IsProperSubset(a, b) == sa.IsProperSubset(*sb) &&
+				Equal(a, b) == sa.Equal(*sb) &&
+				IsDisjoint(a, b) == sa.IsDisjoint(*sb) &&
+				distinct(diff) && sameSet(*NewSet(diff...), sa.SymmetricDifference(*sb))
+		},
+		"slices agree with sets": func(a, b []int8) bool {
+			sa, sb := NewSet(a...), NewSet(b...)
+			union, intersect, except := Union(a, b), Intersect(a, b), ExceptRight(a, b)
+			return distinct(union) && sameSet(*NewSet(union...), sa.Union(*sb)) &&
+				distinct(intersect) && sameSet(*NewSet(intersect...), sa.Intersect(*sb)) &&
+				distinct(except) && sameSet(*NewSet(except...), sa.ExceptRight(*sb))
+		},
+	}
+
+	for name, property := range properties {
+		if err := quick.Check(property, nil); err != nil {
+			fmt.Printf("[ERROR] failed set algebra property:\n\t[property=%s]\n\t[error=%v]\n", name, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestSetFunc(t *testing.T) {
+	set := NewSet(1, 2, 3, 4, 5, 6)
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	evens := FilterSet(set, isEven)
+	if !evens.Equal(*NewSet(2, 4, 6)) {
+		fmt.Printf("[ERROR] failed to filter set:\n\t[got=%v]\n\t[want=%v]\n", evens.Values(), []int{2, 4, 6})
+		t.FailNow()
+	}
+
+	halves := MapSet(set, func(i int) int { return i / 2 })
+	if !halves.Equal(*NewSet(0, 1, 2, 3)) {
+		fmt.Printf("[ERROR] failed to map set:\n\t[got=%v]\n\t[want=%v]\n", halves.Values(), []int{0, 1, 2, 3})
+		t.FailNow()
+	}
+
+	sum := ReduceSet(set, 0, func(acc, i int) int { return acc + i })
+	if sum != 21 {
+		fmt.Printf("[ERROR] failed to reduce set:\n\t[got=%d]\n\t[want=%d]\n", sum, 21)
+		t.FailNow()
+	}
+
+	if !AnySet(set, isEven) || AllSet(set, isEven) || !AllSet(evens, isEven) || AnySet(NewSet[int](), isEven) {
+		fmt.Println("[ERROR] failed to check predicates on set")
+		t.FailNow()
+	}
+
+	matched, unmatched := PartitionSet(set, isEven)
+	if !matched.Equal(*evens) || !unmatched.Equal(*NewSet(1, 3, 5)) {
+		fmt.Printf("[ERROR] failed to partition set:\n\t[matched=%v]\n\t[unmatched=%v]\n", matched.Values(), unmatched.Values())
+		t.FailNow()
+	}
+
+	groups := GroupBySet(set, func(i int) int { return i % 3 })
+	if len(groups) != 3 || !groups[0].Equal(*NewSet(3, 6)) {
+		fmt.Printf("[ERROR] failed to group set:\n\t[got=%v]\n", groups)
+		t.FailNow()
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(2, 3, 4, 5)
+	c := NewSet(3, 4, 6)
+
+	want := NewSet(3, 4)
+	got := IntersectAll(a, b, c)
+	if !got.Equal(*want) {
+		fmt.Printf("[ERROR] failed to intersect all sets:\n\t[got=%v]\n\t[want=%v]\n", got.Values(), want.Values())
+		t.FailNow()
+	}
+
+	want = NewSet(1, 2, 3, 4, 5, 6)
+	got = UnionAll(a, b, c)
+	if !got.Equal(*want) {
+		fmt.Printf("[ERROR] failed to union all sets:\n\t[got=%v]\n\t[want=%v]\n", got.Values(), want.Values())
+		t.FailNow()
+	}
+
+	if IntersectAll(a, NewSet[int](), c).Len() != 0 || IntersectAll[int]().Len() != 0 || UnionAll[int]().Len() != 0 {
+		fmt.Println("[ERROR] expected empty result for empty input")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,190 @@
+package zdutil
+
+import "sort"
+
+// Unique returns the distinct elements of s, in the order they first appear.
+func Unique[T comparable](s []T) []T {
+	res := make([]T, 0, len(s))
+	seen := make(map[T]struct{}, len(s))
+
+	for i := range s {
+		if _, ok := seen[s[i]]; ok {
+			continue
+		}
+
+		seen[s[i]] = struct{}{}
+		res = append(res, s[i])
+	}
+
+	return res
+}
+
+// Compact returns the elements of s that are not the zero value of T, in order.
+func Compact[T comparable](s []T) []T {
+	var zero T
+	res := make([]T, 0, len(s))
+
+	for i := range s {
+		if s[i] != zero {
+			res = append(res, s[i])
+		}
+	}
+
+	return res
+}
+
+// Chunk splits s into consecutive chunks of size elements, the last of which may
+// be shorter. The chunks share memory with s but are capped, so appending to one
+// never overwrites the next. It returns nil if size is less than 1.
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		return nil
+	}
+
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for i := 0; i < len(s); i += size {
+		end := i + size
+		if end > len(s) {
+			end = len(s)
+		}
+		chunks = append(chunks, s[i:end:end])
+	}
+
+	return chunks
+}
+
+// Window returns every run of size consecutive elements of s, sliding by one.
+// The windows share memory with s but are capped, so appending to one never
+// overwrites s. It returns nil if size is less than 1 or greater than len(s).
+func Window[T any](s []T, size int) [][]T {
+	if size < 1 || size > len(s) {
+		return nil
+	}
+
+	windows := make([][]T, 0, len(s)-size+1)
+	for i := 0; i+size <= len(s); i++ {
+		windows = append(windows, s[i:i+size:i+size])
+	}
+
+	return windows
+}
+
+// Flatten concatenates the slices in s into a single new slice.
+func Flatten[T any](s [][]T) []T {
+	size := 0
+	for i := range s {
+		size += len(s[i])
+	}
+
+	res := make([]T, 0, size)
+	for i := range s {
+		res = append(res, s[i]...)
+	}
+
+	return res
+}
+
+// Pair holds two values of possibly different types.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip pairs up the elements of a and b by position.
+// The result is as long as the shorter of the two slices.
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+
+	pairs := make([]Pair[A, B], n)
+	for i := range pairs {
+		pairs[i] = Pair[A, B]{First: a[i], Second: b[i]}
+	}
+
+	return pairs
+}
+
+// Unzip splits pairs back into a slice of first values and a slice of second values.
+func Unzip[A, B any](pairs []Pair[A, B]) ([]A, []B) {
+	a := make([]A, len(pairs))
+	b := make([]B, len(pairs))
+
+	for i := range pairs {
+		a[i] = pairs[i].First
+		b[i] = pairs[i].Second
+	}
+
+	return a, b
+}
+
+// GroupBy splits s into slices keyed by the result of key for each element.
+// Each group keeps the order of s.
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for i := range s {
+		k := key(s[i])
+		groups[k] = append(groups[k], s[i])
+	}
+	return groups
+}
+
+// KeyBy returns a map from the result of key to each element of s.
+// When several elements have the same key the last one wins.
+func KeyBy[T any, K comparable](s []T, key func(T) K) map[K]T {
+	res := make(map[K]T, len(s))
+	for i := range s {
+		res[key(s[i])] = s[i]
+	}
+	return res
+}
+
+// IndexBy returns a map from the result of key to the position of each element of s.
+// When several elements have the same key the last one wins.
+func IndexBy[T any, K comparable](s []T, key func(T) K) map[K]int {
+	res := make(map[K]int, len(s))
+	for i := range s {
+		res[key(s[i])] = i
+	}
+	return res
+}
+
+// Partition splits s into the elements for which pred returns true and the
+// elements for which it returns false, both in the order of s.
+func Partition[T any](s []T, pred func(T) bool) (matched, unmatched []T) {
+	for i := range s {
+		if pred(s[i]) {
+			matched = append(matched, s[i])
+		} else {
+			unmatched = append(unmatched, s[i])
+		}
+	}
+	return matched, unmatched
+}
+
+// sortByKey sorts a slice together with the precomputed keys of its elements.
+type sortByKey[T any, K Ordered] struct {
+	elems []T
+	keys  []K
+}
+
+func (s sortByKey[T, K]) Len() int { return len(s.elems) }
+
+func (s sortByKey[T, K]) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+
+func (s sortByKey[T, K]) Swap(i, j int) {
+	s.elems[i], s.elems[j] = s.elems[j], s.elems[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// SortBy sorts s in place by the key of each element, keeping equal elements in
+// their original order. key is called exactly once per element.
+func SortBy[T any, K Ordered](s []T, key func(T) K) {
+	keys := make([]K, len(s))
+	for i := range s {
+		keys[i] = key(s[i])
+	}
+
+	sort.Stable(sortByKey[T, K]{elems: s, keys: keys})
+}
@@ -0,0 +1,111 @@
+package zdutil
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	s := []int{3, 1, 0, 3, 2, 0, 1}
+
+	type user struct {
+		Name string
+		Age  int
+	}
+	users := []user{{"c", 30}, {"a", 20}, {"b", 30}, {"d", 20}}
+	byAge := func(u user) int { return u.Age }
+
+	sorted := append([]user(nil), users...)
+	SortBy(sorted, byAge)
+
+	a, b := Unzip(Zip([]int{1, 2, 3}, []string{"a", "b"}))
+	matched, unmatched := Partition(s, func(i int) bool { return i > 1 })
+
+	cases := map[string]struct {
+		got  interface{}
+		want string
+	}{
+		"unique":    {Unique(s), "[3 1 0 2]"},
+		"compact":   {Compact(s), "[3 1 3 2 1]"},
+		"chunk":     {Chunk(s, 3), "[[3 1 0] [3 2 0] [1]]"},
+		"window":    {Window(s[:4], 2), "[[3 1] [1 0] [0 3]]"},
+		"flatten":   {Flatten([][]int{{1}, {}, {2, 3}}), "[1 2 3]"},
+		"zip":       {Zip([]int{1, 2, 3}, []string{"a", "b"}), "[{1 a} {2 b}]"},
+		"unzip":     {fmt.Sprint(a, b), "[1 2] [a b]"},
+		"partition": {fmt.Sprint(matched, unmatched), "[3 3 2] [1 0 0 1]"},
+		"group by":  {GroupBy(users, byAge)[30], "[{c 30} {b 30}]"},
+		"key by":    {KeyBy(users, byAge)[20], "{d 20}"},
+		"index by":  {IndexBy(users, byAge)[30], "2"},
+		"sort by":   {sorted, "[{a 20} {d 20} {c 30} {b 30}]"},
+	}
+
+	for name, c := range cases {
+		if got := fmt.Sprint(c.got); got != c.want {
+			fmt.Printf("[ERROR] failed %s:\n\t[got=%s]\n\t[want=%s]\n", name, got, c.want)
+			t.Fail()
+		}
+	}
+
+	chunks := Chunk(s, 3)
+	chunks[0] = append(chunks[0], 99)
+	if s[3] != 3 {
+		fmt.Println("[ERROR] appending to a chunk overwrote the next chunk")
+		t.FailNow()
+	}
+}
+
+func benchSlice() []int {
+	s := make([]int, 10000)
+	for i := range s {
+		s[i] = i % 1000
+	}
+	return s
+}
+
+func BenchmarkUnique(b *testing.B) {
+	s := benchSlice()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Unique(s)
+	}
+}
+
+func BenchmarkChunk(b *testing.B) {
+	s := benchSlice()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Chunk(s, 64)
+	}
+}
+
+func BenchmarkFlatten(b *testing.B) {
+	chunks := Chunk(benchSlice(), 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Flatten(chunks)
+	}
+}
+
+func BenchmarkGroupBy(b *testing.B) {
+	s := benchSlice()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GroupBy(s, func(v int) int { return v % 10 })
+	}
+}
+
+func BenchmarkSortBy(b *testing.B) {
+	s := benchSlice()
+	work := make([]int, len(s))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(work, s)
+		SortBy(work, func(v int) string { return strconv.Itoa(v) })
+	}
+}
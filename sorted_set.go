@@ -0,0 +1,387 @@
+package zdutil
+
+import "sort"
+
+type sortedNode[T any] struct {
+	value       T
+	left, right *sortedNode[T]
+	height      int
+	size        int
+}
+
+// SortedSet is a set that keeps its elements sorted. It is backed by an AVL tree
+// where every node also tracks the size of its subtree, so that Add, Remove,
+// Contains, Floor, Ceiling, Rank and At all run in logarithmic time.
+// Sets must be created with NewSortedSet or NewSortedSetFunc, which provide the
+// ordering. The zero value is an empty set that panics when elements are added.
+type SortedSet[T any] struct {
+	root    *sortedNode[T]
+	compare func(a, b T) int
+}
+
+// NewSortedSet creates a new sorted set containing the specified elements,
+// ordered by the natural order of T.
+func NewSortedSet[T Ordered](elems ...T) *SortedSet[T] {
+	return NewSortedSetFunc(compareOrdered[T], elems...)
+}
+
+// NewSortedSetFunc creates a new sorted set containing the specified elements,
+// ordered by compare. compare must return a negative number when a sorts before b,
+// a positive number when a sorts after b and zero when a and b are the same element.
+func NewSortedSetFunc[T any](compare func(a, b T) int, elems ...T) *SortedSet[T] {
+	s := &SortedSet[T]{compare: compare}
+	s.Add(elems...)
+
+	return s
+}
+
+// SetToSortedSet returns a SortedSet containing the elements of s in their natural order.
+func SetToSortedSet[T Ordered](s *Set[T]) *SortedSet[T] {
+	return NewSortedSet(s.Values()...)
+}
+
+// SortedSetToSet returns an unordered Set containing the elements of s.
+func SortedSetToSet[T comparable](s *SortedSet[T]) *Set[T] {
+	set := &Set[T]{elements: make(map[T]struct{}, s.Len())}
+	s.Each(func(elem T) bool {
+		set.elements[elem] = struct{}{}
+		return true
+	})
+	return set
+}
+
+// SortedValues returns all elements in the set sorted in their natural order.
+func SortedValues[T Ordered](s *Set[T]) []T {
+	values := s.Values()
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
+
+// SortedValuesFunc returns all elements in the set sorted by less.
+func SortedValuesFunc[T comparable](s *Set[T], less func(a, b T) bool) []T {
+	values := s.Values()
+	sort.Slice(values, func(i, j int) bool { return less(values[i], values[j]) })
+	return values
+}
+
+// Add adds the specified elements to the set.
+//
+// The function is a no-op if any of the specified elements already exist in the set.
+func (s *SortedSet[T]) Add(elems ...T) {
+	if s.compare == nil && len(elems) > 0 {
+		panic("zdutil: Add called on SortedSet not created with NewSortedSet or NewSortedSetFunc")
+	}
+
+	for i := range elems {
+		s.root = s.insert(s.root, elems[i])
+	}
+}
+
+// Remove deletes the specified element from the set.
+// If the element does not exist in the set, the function does nothing.
+func (s *SortedSet[T]) Remove(element T) {
+	s.root = s.remove(s.root, element)
+}
+
+// Contains checks if the set contains all of the given elements, following the
+// same rules as Set.Contains.
+func (s *SortedSet[T]) Contains(elems ...T) bool {
+	if len(elems) == 0 && s.Len() > 0 {
+		return false
+	}
+
+	for i := range elems {
+		if s.find(elems[i]) == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Len returns the number of elements in the set.
+func (s *SortedSet[T]) Len() int {
+	return nodeSize(s.root)
+}
+
+// Clear resets the set to its initial state, removing all elements.
+func (s *SortedSet[T]) Clear() {
+	s.root = nil
+}
+
+// Values returns all elements in the set, in sorted order.
+func (s *SortedSet[T]) Values() []T {
+	values := make([]T, 0, s.Len())
+	s.Each(func(elem T) bool {
+		values = append(values, elem)
+		return true
+	})
+	return values
+}
+
+// Each calls fn for every element in the set, in sorted order, until fn returns false.
+func (s *SortedSet[T]) Each(fn func(T) bool) {
+	var stack []*sortedNode[T]
+
+	n := s.root
+	for n != nil || len(stack) > 0 {
+		for n != nil {
+			stack = append(stack, n)
+			n = n.left
+		}
+
+		n = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !fn(n.value) {
+			return
+		}
+		n = n.right
+	}
+}
+
+// Min returns the smallest element and true, or the zero value and false if the set is empty.
+func (s *SortedSet[T]) Min() (T, bool) {
+	return s.At(0)
+}
+
+// Max returns the largest element and true, or the zero value and false if the set is empty.
+func (s *SortedSet[T]) Max() (T, bool) {
+	return s.At(s.Len() - 1)
+}
+
+// Floor returns the largest element less than or equal to x and true,
+// or the zero value and false if there is no such element.
+func (s *SortedSet[T]) Floor(x T) (T, bool) {
+	var floor T
+	found := false
+
+	for n := s.root; n != nil; {
+		c := s.compare(x, n.value)
+		switch {
+		case c == 0:
+			return n.value, true
+		case c < 0:
+			n = n.left
+		default:
+			floor, found = n.value, true
+			n = n.right
+		}
+	}
+
+	return floor, found
+}
+
+// Ceiling returns the smallest element greater than or equal to x and true,
+// or the zero value and false if there is no such element.
+func (s *SortedSet[T]) Ceiling(x T) (T, bool) {
+	var ceiling T
+	found := false
+
+	for n := s.root; n != nil; {
+		c := s.compare(x, n.value)
+		switch {
+		case c == 0:
+			return n.value, true
+		case c > 0:
+			n = n.right
+		default:
+			ceiling, found = n.value, true
+			n = n.left
+		}
+	}
+
+	return ceiling, found
+}
+
+// Range returns every element between lo and hi, both inclusive, in sorted order.
+func (s *SortedSet[T]) Range(lo, hi T) []T {
+	var values []T
+	s.collectRange(s.root, lo, hi, &values)
+	return values
+}
+
+// Rank returns the number of elements in the set that are less than x.
+// When x exists in the set this is its position in sorted order.
+func (s *SortedSet[T]) Rank(x T) int {
+	rank := 0
+
+	for n := s.root; n != nil; {
+		if s.compare(x, n.value) <= 0 {
+			n = n.left
+			continue
+		}
+
+		rank += nodeSize(n.left) + 1
+		n = n.right
+	}
+
+	return rank
+}
+
+// At returns the k-th smallest element, counting from zero, and true,
+// or the zero value and false if k is out of range.
+func (s *SortedSet[T]) At(k int) (T, bool) {
+	var zero T
+	if k < 0 || k >= s.Len() {
+		return zero, false
+	}
+
+	n := s.root
+	for {
+		left := nodeSize(n.left)
+		switch {
+		case k < left:
+			n = n.left
+		case k == left:
+			return n.value, true
+		default:
+			k -= left + 1
+			n = n.right
+		}
+	}
+}
+
+func (s *SortedSet[T]) find(x T) *sortedNode[T] {
+	n := s.root
+	for n != nil {
+		c := s.compare(x, n.value)
+		switch {
+		case c == 0:
+			return n
+		case c < 0:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return nil
+}
+
+func (s *SortedSet[T]) collectRange(n *sortedNode[T], lo, hi T, values *[]T) {
+	if n == nil {
+		return
+	}
+
+	afterLo := s.compare(n.value, lo) >= 0
+	beforeHi := s.compare(n.value, hi) <= 0
+
+	if afterLo {
+		s.collectRange(n.left, lo, hi, values)
+	}
+	if afterLo && beforeHi {
+		*values = append(*values, n.value)
+	}
+	if beforeHi {
+		s.collectRange(n.right, lo, hi, values)
+	}
+}
+
+func (s *SortedSet[T]) insert(n *sortedNode[T], x T) *sortedNode[T] {
+	if n == nil {
+		return &sortedNode[T]{value: x, height: 1, size: 1}
+	}
+
+	c := s.compare(x, n.value)
+	switch {
+	case c == 0:
+		return n
+	case c < 0:
+		n.left = s.insert(n.left, x)
+	default:
+		n.right = s.insert(n.right, x)
+	}
+
+	return rebalance(n)
+}
+
+func (s *SortedSet[T]) remove(n *sortedNode[T], x T) *sortedNode[T] {
+	if n == nil {
+		return nil
+	}
+
+	c := s.compare(x, n.value)
+	switch {
+	case c < 0:
+		n.left = s.remove(n.left, x)
+	case c > 0:
+		n.right = s.remove(n.right, x)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		n.value = successor.value
+		n.right = s.remove(n.right, successor.value)
+	}
+
+	return rebalance(n)
+}
+
+func nodeHeight[T any](n *sortedNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func nodeSize[T any](n *sortedNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func updateNode[T any](n *sortedNode[T]) {
+	left, right := nodeHeight(n.left), nodeHeight(n.right)
+	if left > right {
+		n.height = left + 1
+	} else {
+		n.height = right + 1
+	}
+	n.size = nodeSize(n.left) + nodeSize(n.right) + 1
+}
+
+func rotateLeft[T any](n *sortedNode[T]) *sortedNode[T] {
+	r := n.right
+	n.right = r.left
+	updateNode(n)
+	r.left = n
+	updateNode(r)
+	return r
+}
+
+func rotateRight[T any](n *sortedNode[T]) *sortedNode[T] {
+	l := n.left
+	n.left = l.right
+	updateNode(n)
+	l.right = n
+	updateNode(l)
+	return l
+}
+
+func rebalance[T any](n *sortedNode[T]) *sortedNode[T] {
+	updateNode(n)
+
+	balance := nodeHeight(n.left) - nodeHeight(n.right)
+	switch {
+	case balance > 1:
+		if nodeHeight(n.left.left) < nodeHeight(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if nodeHeight(n.right.right) < nodeHeight(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+
+	return n
+}
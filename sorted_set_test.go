@@ -0,0 +1,107 @@
+package zdutil
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSortedSet(t *testing.T) {
+	set := NewSortedSet(50, 10, 40, 20, 30, 10)
+
+	want := []int{10, 20, 30, 40, 50}
+	got := set.Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to sort elements:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	min, _ := set.Min()
+	max, _ := set.Max()
+	floor, _ := set.Floor(35)
+	ceiling, _ := set.Ceiling(35)
+	if min != 10 || max != 50 || floor != 30 || ceiling != 40 {
+		fmt.Printf("[ERROR] failed to find bounds:\n\t[min=%d]\n\t[max=%d]\n\t[floor=%d]\n\t[ceiling=%d]\n", min, max, floor, ceiling)
+		t.FailNow()
+	}
+
+	if _, ok := set.Floor(5); ok {
+		fmt.Println("[ERROR] expected no floor below the smallest element")
+		t.FailNow()
+	}
+
+	want = []int{20, 30, 40}
+	got = set.Range(15, 40)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to get range:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	if set.Rank(30) != 2 || set.Rank(35) != 3 {
+		fmt.Printf("[ERROR] failed to rank elements:\n\t[got=%d,%d]\n\t[want=%d,%d]\n", set.Rank(30), set.Rank(35), 2, 3)
+		t.FailNow()
+	}
+
+	want = []int{10, 20, 30, 40, 50}
+	got = SortedValues(SortedSetToSet(set))
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] failed to convert sorted set:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+}
+
+func TestSortedSetRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	set := NewSortedSet[int]()
+	oracle := NewSet[int]()
+
+	for i := 0; i < 5000; i++ {
+		v := r.Intn(500)
+		if r.Intn(3) == 0 {
+			set.Remove(v)
+			oracle.Remove(v)
+		} else {
+			set.Add(v)
+			oracle.Add(v)
+		}
+	}
+
+	want := oracle.Values()
+	sort.Ints(want)
+	got := set.Values()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("[ERROR] sorted set diverged from oracle:\n\t[got=%v]\n\t[want=%v]\n", got, want)
+		t.FailNow()
+	}
+
+	for i := range want {
+		if elem, ok := set.At(i); !ok || elem != want[i] || set.Rank(want[i]) != i {
+			fmt.Printf("[ERROR] failed to index element:\n\t[index=%d]\n\t[got=%d]\n\t[want=%d]\n", i, elem, want[i])
+			t.FailNow()
+		}
+	}
+
+	// an AVL tree is never taller than about 1.44 log2(n)
+	if nodeHeight(set.root) > 14 {
+		fmt.Printf("[ERROR] tree is not balanced:\n\t[height=%d]\n\t[len=%d]\n", nodeHeight(set.root), set.Len())
+		t.FailNow()
+	}
+}
+
+func TestSortedSetZeroValue(t *testing.T) {
+	var set SortedSet[int]
+	if set.Len() != 0 || set.Contains(1) {
+		fmt.Println("[ERROR] expected zero value sorted set to be empty")
+		t.FailNow()
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "NewSortedSet") {
+			fmt.Printf("[ERROR] expected a clear panic when adding to a zero value sorted set:\n\t[panic=%v]\n", r)
+			t.FailNow()
+		}
+	}()
+	set.Add(1)
+}
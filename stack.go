@@ -1,6 +1,7 @@
 package zdutil
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
 )
@@ -73,6 +74,21 @@ func (s *Stack[T]) Len() int {
 	return len(s.slice)
 }
 
+// Each calls fn for every element from the top to the bottom of the stack,
+// until fn returns false. It iterates over a copy taken under the lock, so fn
+// may safely modify the stack.
+func (s *Stack[T]) Each(fn func(T) bool) {
+	s.m.Lock()
+	slice := append([]T(nil), s.slice...)
+	s.m.Unlock()
+
+	for i := range slice {
+		if !fn(slice[i]) {
+			return
+		}
+	}
+}
+
 // String returns a string representation of the stack.
 // The string is formatted as "[x,y,z]" where x, y, and z are the elements of the stack.
 // The function acquires a lock to ensure thread-safe access.
@@ -93,3 +109,81 @@ func (s *Stack[T]) String() string {
 	str += "]"
 	return str
 }
+
+// MarshalJSON encodes the stack as a JSON array ordered from top to bottom.
+// The function acquires a lock to ensure thread-safe access.
+func (s *Stack[T]) MarshalJSON() ([]byte, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.slice == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(s.slice)
+}
+
+// UnmarshalJSON replaces the contents of the stack with the decoded JSON array.
+// The first element of the array becomes the top of the stack.
+// The function acquires a lock to ensure thread-safe access.
+func (s *Stack[T]) UnmarshalJSON(data []byte) error {
+	var slice []T
+	if err := json.Unmarshal(data, &slice); err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.slice = slice
+	return nil
+}
+
+// GobEncode encodes the stack as a gob stream ordered from top to bottom.
+// The function acquires a lock to ensure thread-safe access.
+func (s *Stack[T]) GobEncode() ([]byte, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return gobEncodeValues(s.slice)
+}
+
+// GobDecode replaces the contents of the stack with the decoded gob stream.
+// The function acquires a lock to ensure thread-safe access.
+func (s *Stack[T]) GobDecode(data []byte) error {
+	slice, err := gobDecodeValues[T](data)
+	if err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.slice = slice
+	return nil
+}
+
+// MarshalText encodes the stack in the same "[x,y,z]" layout as String.
+// It returns an error if T is not a string, boolean, number or encoding.TextMarshaler.
+// The function acquires a lock to ensure thread-safe access.
+func (s *Stack[T]) MarshalText() ([]byte, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return marshalTextValues(s.slice)
+}
+
+// trim keeps at most n elements from the top of the stack and returns the dropped
+// ones, from the top down. It acquires a lock to ensure thread-safe access.
+func (s *Stack[T]) trim(n int) []T {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if n < 0 || len(s.slice) <= n {
+		return nil
+	}
+
+	dropped := s.slice[n:]
+	s.slice = s.slice[:n:n]
+	return dropped
+}
@@ -0,0 +1,47 @@
+package zdutil
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestStackEncoding(t *testing.T) {
+	stack := NewStack[string]()
+	stack.Push("c")
+	stack.Push("b")
+	stack.Push("a")
+
+	want := `["a","b","c"]`
+	data, err := json.Marshal(&stack)
+	if err != nil || string(data) != want {
+		fmt.Printf("[ERROR] failed to marshal stack to json:\n\t[got=%s]\n\t[want=%s]\n\t[error=%v]\n", data, want, err)
+		t.FailNow()
+	}
+
+	var got Stack[string]
+	if err := json.Unmarshal(data, &got); err != nil || got.String() != stack.String() {
+		fmt.Printf("[ERROR] failed to unmarshal stack from json:\n\t[got=%s]\n\t[want=%s]\n\t[error=%v]\n", got.String(), stack.String(), err)
+		t.FailNow()
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&stack); err != nil {
+		fmt.Printf("[ERROR] failed to gob encode stack:\n\t[error=%v]\n", err)
+		t.FailNow()
+	}
+
+	var gobgot Stack[string]
+	if err := gob.NewDecoder(&buf).Decode(&gobgot); err != nil || gobgot.String() != stack.String() {
+		fmt.Printf("[ERROR] failed to gob decode stack:\n\t[got=%s]\n\t[want=%s]\n\t[error=%v]\n", gobgot.String(), stack.String(), err)
+		t.FailNow()
+	}
+
+	data, err = stack.MarshalText()
+	if err != nil || string(data) != stack.String() {
+		fmt.Printf("[ERROR] failed to marshal stack to text:\n\t[got=%s]\n\t[want=%s]\n\t[error=%v]\n", data, stack.String(), err)
+		t.FailNow()
+	}
+}